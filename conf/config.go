@@ -1,47 +1,139 @@
 package conf
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
-	"log"
+	"os"
 	"sync"
 )
 
 type Config struct {
 
 	// APPLICATION
-	APP_NAME string `json:"app_name"`
+	APP_NAME string `json:"app_name" default:"Vaccination Drive"`
+	DEV_MODE bool   `json:"dev_mode"` // allows the development fakes, the stderr sms_provider and an in memory jwt key
 
 	// SERVER CONFIG
 	ADDRESS string `json:"http_address"` // http address to listern  Eg : http://localhost
-	PORT    int    `json:"port"`
+	PORT    int    `json:"port" default:"8085"`
 
 	// DATABASE CONFIG
 	DB_TYPE                  string `json:"type"`
-	DB_NAME                  string `json:"db_name"`
-	DB_USERNAME              string `json:"username"`
-	DB_PASSWORD              string `json:"password"`
-	DB_ADDRESS               string `json:"db_address"`
-	Max_Connection_Pool_Size int    `json:"max_connection_pool_size"`
+	DB_NAME                  string `json:"db_name" required:"true"`
+	DB_USERNAME              string `json:"username" required:"true"`
+	DB_PASSWORD              string `json:"password" secret:"true"`
+	DB_ADDRESS               string `json:"db_address" default:"localhost:5432"`
+	Max_Connection_Pool_Size int    `json:"max_connection_pool_size" default:"10"`
+
+	// TRANSACTION CONFIG, retries of transactions aborted by a serialization failure or a deadlock
+	TX_MAX_RETRIES         int `json:"tx_max_retries" default:"3" reload:"true"`
+	TX_RETRY_BASE_DELAY_MS int `json:"tx_retry_base_delay_ms" default:"20" reload:"true"` // doubled on each retry, jittered
+	TX_RETRY_MAX_DELAY_MS  int `json:"tx_retry_max_delay_ms" default:"500" reload:"true"`
+
+	// QUERY TIMEOUT CONFIG, the db work of a request is canceled after the timeout of its route
+	QUERY_TIMEOUT_MS     int            `json:"query_timeout_ms" default:"10000" reload:"true"`
+	ROUTE_QUERY_TIMEOUTS map[string]int `json:"route_query_timeouts" reload:"true"` // by "METHOD /path", Eg. "GET /centers/:id/appointments": 30000
+
+	// ACCOUNT CONFIG
+	MAX_BENEFICIARIES_PER_ACCOUNT int `json:"max_beneficiaries_per_account" default:"4" reload:"true"`
+
+	// APPOINTMENT CONFIG
+	CANCELLATION_CUTOFF_HOURS int `json:"cancellation_cutoff_hours" reload:"true"`        // no cancellation within these many hours of the slot
+	BOOKING_WINDOW_DAYS       int `json:"booking_window_days" default:"90" reload:"true"` // appointments can be booked these many days ahead
+
+	// CAPACITY CONFIG, used when capacity is published without quotas
+	DEFAULT_DAILY_QUOTA int            `json:"default_daily_quota" reload:"true"`
+	DEFAULT_SLOT_QUOTAS map[string]int `json:"default_slot_quotas" reload:"true"`
+
+	// LOG CONFIG
+	LOG_LEVEL string `json:"log_level" default:"Info" reload:"true"` // Debug, Info, Warn, Error or Fatal
+
+	// AUTH CONFIG
+	OTP_SECRET                  string `json:"otp_secret" required:"true" secret:"true"` // HMAC key used to hash the OTPs
+	OTP_EXPIRY_MINUTES          int    `json:"otp_expiry_minutes" default:"5"`
+	OTP_MAX_ATTEMPTS            int    `json:"otp_max_attempts" default:"5"`
+	OTP_RESEND_INTERVAL_SECONDS int    `json:"otp_resend_interval_seconds" default:"30"`
+	OTP_MAX_SENDS_PER_HOUR      int    `json:"otp_max_sends_per_hour" default:"5"`
+
+	// TOKEN CONFIG
+	JWT_ISSUER               string   `json:"jwt_issuer"`
+	JWT_SIGNING_KID          string   `json:"jwt_signing_kid"` // kid of the key signing new tokens, the others only verify
+	JWT_KEYS                 []JWTKey `json:"jwt_keys"`        // required outside dev_mode
+	ACCESS_TOKEN_TTL_MINUTES int      `json:"access_token_ttl_minutes" default:"15"`
+	REFRESH_TOKEN_TTL_HOURS  int      `json:"refresh_token_ttl_hours" default:"720"`
+
+	// PII CONFIG
+	PII_KEYS        []PIIKey `json:"pii_keys" required:"true" secret:"true"`
+	PII_KEY_VERSION int      `json:"pii_key_version" required:"true"`            // version of the key encrypting new values, the others only decrypt
+	PII_HASH_KEY    string   `json:"pii_hash_key" required:"true" secret:"true"` // base64 HMAC key of the lookup hashes, changing it breaks uniqueness checks
+
+	// SMS CONFIG
+	SMS_PROVIDER  string `json:"sms_provider" required:"true"` // file, or stderr in dev_mode
+	SMS_FILE_PATH string `json:"sms_file_path"`                // messages are appended here by the file provider
+}
+
+//JWTKey points to the PEM files of a token signing key, a retired key keeps only its public key
+type JWTKey struct {
+	KID            string `json:"kid"`
+	PrivateKeyFile string `json:"private_key_file"`
+	PublicKeyFile  string `json:"public_key_file"`
+}
+
+//PIIKey is a base64 encoded AES-256 key, old versions are kept to decrypt existing values
+type PIIKey struct {
+	Version int    `json:"version"`
+	Key     string `json:"key" secret:"true"`
 }
 
 var (
-	Cfg  Config
-	once sync.Once
+	Cfg      Config
+	once     sync.Once
+	parseErr error
 )
 
-// Parse parses the json configuration file
-// And converting it into native type
+//Parse reads the json configuration file into Cfg once, see Load
 func Parse(file string) error {
 	once.Do(func() {
-		// Reading the flags
-		data, err := ioutil.ReadFile(file)
-		if err != nil {
-			log.Println("Error in ReadFile:", err)
-		}
-		if err := json.Unmarshal(data, &Cfg); err != nil {
-			log.Println("Error in Unmarshal:", err)
+		var cfg *Config
+		if cfg, parseErr = Load(file); parseErr == nil {
+			Cfg = *cfg
 		}
 	})
-	return nil
+	return parseErr
+}
+
+//Load reads the json configuration file. Defaults are set first so that the zero
+//values given in the file or the environment are kept, unknown fields are rejected,
+//the environment overrides the file (see applyEnv) and the required fields are checked.
+func Load(file string) (*Config, error) {
+	cfg := &Config{}
+	if err := load(file, cfg, os.LookupEnv); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func load(file string, cfg *Config, lookup func(string) (string, bool)) error {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("config: %v", err)
+	}
+
+	if err := applyDefaults(cfg); err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(cfg); err != nil {
+		return fmt.Errorf("config: %s: %v", file, err)
+	}
+
+	if err := applyEnv(cfg, lookup); err != nil {
+		return err
+	}
+
+	return cfg.Validate()
 }
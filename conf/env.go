@@ -0,0 +1,243 @@
+package conf
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+//EnvPrefix is prepended to the field names of Config to get their environment variables,
+//Eg. VACCINATION_DB_PASSWORD overrides DB_PASSWORD
+const EnvPrefix = "VACCINATION_"
+
+//redacted replaces the secrets in the output of Redacted
+const redacted = "REDACTED"
+
+//EnvName returns the environment variable of a Config field
+func EnvName(field string) string {
+	return EnvPrefix + strings.ToUpper(field)
+}
+
+//applyEnv overrides the fields set in the environment. Lists are given as json.
+//Secrets are also read from the file named by the variable with a _FILE suffix,
+//Eg. VACCINATION_DB_PASSWORD_FILE=/run/secrets/db_password
+func applyEnv(cfg *Config, lookup func(string) (string, bool)) error {
+	v := reflect.ValueOf(cfg).Elem()
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := EnvName(field.Name)
+
+		value, ok := lookup(name)
+		if path, fok := lookup(name + "_FILE"); fok && field.Tag.Get("secret") == "true" {
+			if ok {
+				return fmt.Errorf("config: both %s and %s_FILE are set", name, name)
+			}
+
+			data, err := ioutil.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("config: %s_FILE: %v", name, err)
+			}
+			value, ok = strings.TrimRight(string(data), "\r\n"), true
+		}
+		if !ok {
+			continue
+		}
+
+		if err := setValue(v.Field(i), value); err != nil {
+			return fmt.Errorf("config: %s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+//applyDefaults sets the fields with a default tag to their default, it runs before
+//the file and the environment are read so that they can set a field back to zero
+func applyDefaults(cfg *Config) error {
+	v := reflect.ValueOf(cfg).Elem()
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		def, ok := t.Field(i).Tag.Lookup("default")
+		if !ok {
+			continue
+		}
+
+		if err := setValue(v.Field(i), def); err != nil {
+			return fmt.Errorf("config: default of %s: %v", t.Field(i).Name, err)
+		}
+	}
+	return nil
+}
+
+func setValue(f reflect.Value, value string) error {
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(value)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return errors.New("should be a number")
+		}
+		f.SetInt(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return errors.New("should be true or false")
+		}
+		f.SetBool(b)
+	default:
+		if err := json.Unmarshal([]byte(value), f.Addr().Interface()); err != nil {
+			return fmt.Errorf("should be json: %v", err)
+		}
+	}
+	return nil
+}
+
+//Validate checks the required fields and the values the server can't start with
+func (c Config) Validate() error {
+	v := reflect.ValueOf(c)
+	t := v.Type()
+
+	missing := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("required") == "true" && v.Field(i).IsZero() {
+			missing = append(missing, strings.Split(t.Field(i).Tag.Get("json"), ",")[0])
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("config: %s required", strings.Join(missing, ", "))
+	}
+
+	if c.PORT < 1 || c.PORT > 65535 {
+		return fmt.Errorf("config: port %d is not valid", c.PORT)
+	}
+
+	if c.Max_Connection_Pool_Size < 1 {
+		return errors.New("config: max_connection_pool_size should be at least 1")
+	}
+
+	if err := c.validateRuntime(); err != nil {
+		return err
+	}
+
+	if c.OTP_EXPIRY_MINUTES < 1 || c.OTP_MAX_ATTEMPTS < 1 || c.OTP_MAX_SENDS_PER_HOUR < 1 {
+		return errors.New("config: otp_expiry_minutes, otp_max_attempts and otp_max_sends_per_hour should be at least 1")
+	}
+
+	if c.OTP_RESEND_INTERVAL_SECONDS < 0 {
+		return errors.New("config: otp_resend_interval_seconds should not be negative")
+	}
+
+	if c.ACCESS_TOKEN_TTL_MINUTES < 1 || c.REFRESH_TOKEN_TTL_HOURS < 1 {
+		return errors.New("config: access_token_ttl_minutes and refresh_token_ttl_hours should be at least 1")
+	}
+
+	if len(c.JWT_KEYS) == 0 && !c.DEV_MODE {
+		return errors.New("config: jwt_keys are required, an in memory key is only allowed with dev_mode")
+	}
+
+	switch c.SMS_PROVIDER {
+	case "stderr":
+		if !c.DEV_MODE {
+			return errors.New("config: the stderr sms_provider is only allowed with dev_mode")
+		}
+	case "file":
+		if c.SMS_FILE_PATH == "" {
+			return errors.New("config: sms_file_path is required for the file sms_provider")
+		}
+	default:
+		return fmt.Errorf("config: unknown sms_provider %s", c.SMS_PROVIDER)
+	}
+
+	return nil
+}
+
+//logLevels are the levels of the request logger
+var logLevels = map[string]bool{"Debug": true, "Info": true, "Warn": true, "Error": true, "Fatal": true}
+
+//validateRuntime checks the fields that can be reloaded while the server runs
+func (c Config) validateRuntime() error {
+	if c.BOOKING_WINDOW_DAYS < 1 {
+		return errors.New("config: booking_window_days should be at least 1")
+	}
+
+	if c.MAX_BENEFICIARIES_PER_ACCOUNT < 1 {
+		return errors.New("config: max_beneficiaries_per_account should be at least 1")
+	}
+
+	if c.CANCELLATION_CUTOFF_HOURS < 0 {
+		return errors.New("config: cancellation_cutoff_hours should not be negative")
+	}
+
+	if c.DEFAULT_DAILY_QUOTA < 0 {
+		return errors.New("config: default_daily_quota should not be negative")
+	}
+
+	for slot, quota := range c.DEFAULT_SLOT_QUOTAS {
+		if quota < 0 || (c.DEFAULT_DAILY_QUOTA > 0 && quota > c.DEFAULT_DAILY_QUOTA) {
+			return fmt.Errorf("config: default_slot_quotas[%s] should be between 0 and default_daily_quota", slot)
+		}
+	}
+
+	if c.TX_MAX_RETRIES < 0 {
+		return errors.New("config: tx_max_retries should not be negative")
+	}
+
+	if c.TX_RETRY_BASE_DELAY_MS < 1 || c.TX_RETRY_MAX_DELAY_MS < c.TX_RETRY_BASE_DELAY_MS {
+		return errors.New("config: tx_retry_base_delay_ms should be at least 1 and not more than tx_retry_max_delay_ms")
+	}
+
+	if c.QUERY_TIMEOUT_MS < 1 {
+		return errors.New("config: query_timeout_ms should be at least 1")
+	}
+
+	for route, timeout := range c.ROUTE_QUERY_TIMEOUTS {
+		if len(strings.Fields(route)) != 2 || timeout < 1 {
+			return fmt.Errorf("config: route_query_timeouts[%s] should be keyed by \"METHOD /path\" with a timeout of at least 1", route)
+		}
+	}
+
+	if !logLevels[c.LOG_LEVEL] {
+		return fmt.Errorf("config: log_level %s is not one of Debug, Info, Warn, Error or Fatal", c.LOG_LEVEL)
+	}
+
+	return nil
+}
+
+//Redacted returns a copy of the config with the secrets replaced, for printing
+func (c Config) Redacted() Config {
+	//round trip through json so the lists are copied before redacting
+	data, _ := json.Marshal(c)
+	cp := Config{}
+	json.Unmarshal(data, &cp)
+
+	redact(reflect.ValueOf(&cp).Elem())
+	return cp
+}
+
+func redact(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := v.Field(i)
+			if t.Field(i).Tag.Get("secret") == "true" && f.Kind() == reflect.String {
+				if f.String() != "" {
+					f.SetString(redacted)
+				}
+				continue
+			}
+			redact(f)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			redact(v.Index(i))
+		}
+	}
+}
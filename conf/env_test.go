@@ -0,0 +1,234 @@
+package conf
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+//baseJSON is the smallest config file that passes Validate
+const baseJSON = `{
+	"dev_mode": true,
+	"db_name": "vaccination",
+	"username": "vaccination",
+	"otp_secret": "otp-secret",
+	"pii_keys": [{"version": 1, "key": "pii-key"}],
+	"pii_key_version": 1,
+	"pii_hash_key": "hash-key",
+	"sms_provider": "stderr"`
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func env(vars map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, ok := vars[name]
+		return value, ok
+	}
+}
+
+//validConfig returns a config which passes Validate
+func validConfig(t *testing.T) Config {
+	t.Helper()
+
+	cfg := Config{}
+	if err := load(writeFile(t, "config.json", baseJSON+"}"), &cfg, env(nil)); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	return cfg
+}
+
+func TestApplyEnv(t *testing.T) {
+	secret := writeFile(t, "db_password", "from-file\n")
+
+	tests := []struct {
+		name  string
+		vars  map[string]string
+		check func(Config) bool
+		err   string
+	}{
+		{"string", map[string]string{"VACCINATION_DB_NAME": "other"},
+			func(c Config) bool { return c.DB_NAME == "other" }, ""},
+		{"number", map[string]string{"VACCINATION_PORT": "9090"},
+			func(c Config) bool { return c.PORT == 9090 }, ""},
+		{"zero number", map[string]string{"VACCINATION_TX_MAX_RETRIES": "0"},
+			func(c Config) bool { return c.TX_MAX_RETRIES == 0 }, ""},
+		{"bool", map[string]string{"VACCINATION_DEV_MODE": "true"},
+			func(c Config) bool { return c.DEV_MODE }, ""},
+		{"json list", map[string]string{"VACCINATION_PII_KEYS": `[{"version":2,"key":"k"}]`},
+			func(c Config) bool { return len(c.PII_KEYS) == 1 && c.PII_KEYS[0].Version == 2 }, ""},
+		{"secret file", map[string]string{"VACCINATION_DB_PASSWORD_FILE": secret},
+			func(c Config) bool { return c.DB_PASSWORD == "from-file" }, ""},
+		{"file of a field which is not secret", map[string]string{"VACCINATION_DB_NAME_FILE": secret},
+			func(c Config) bool { return c.DB_NAME == "" }, ""},
+		{"unset", nil,
+			func(c Config) bool { return reflect.DeepEqual(c, Config{}) }, ""},
+		{"invalid number", map[string]string{"VACCINATION_PORT": "http"}, nil, "VACCINATION_PORT: should be a number"},
+		{"invalid bool", map[string]string{"VACCINATION_DEV_MODE": "yes please"}, nil, "VACCINATION_DEV_MODE: should be true or false"},
+		{"invalid json", map[string]string{"VACCINATION_PII_KEYS": "key"}, nil, "VACCINATION_PII_KEYS: should be json"},
+		{"secret and file both set", map[string]string{"VACCINATION_DB_PASSWORD": "pw", "VACCINATION_DB_PASSWORD_FILE": secret},
+			nil, "both VACCINATION_DB_PASSWORD and VACCINATION_DB_PASSWORD_FILE are set"},
+		{"missing secret file", map[string]string{"VACCINATION_DB_PASSWORD_FILE": filepath.Join(t.TempDir(), "missing")},
+			nil, "VACCINATION_DB_PASSWORD_FILE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{}
+			err := applyEnv(&cfg, env(tt.vars))
+
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("applyEnv error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyEnv error = %v", err)
+			}
+			if !tt.check(cfg) {
+				t.Errorf("applyEnv config = %+v", cfg)
+			}
+		})
+	}
+}
+
+func TestLoadDefaults(t *testing.T) {
+	tests := []struct {
+		name  string
+		file  string
+		vars  map[string]string
+		check func(Config) bool
+	}{
+		{"omitted fields get their default", "", nil, func(c Config) bool {
+			return c.TX_MAX_RETRIES == 3 && c.PORT == 8085 && c.LOG_LEVEL == "Info" && c.APP_NAME == "Vaccination Drive"
+		}},
+		{"zero in the file is kept", `, "tx_max_retries": 0`, nil, func(c Config) bool {
+			return c.TX_MAX_RETRIES == 0
+		}},
+		{"zero in the environment is kept", "", map[string]string{"VACCINATION_TX_MAX_RETRIES": "0"}, func(c Config) bool {
+			return c.TX_MAX_RETRIES == 0
+		}},
+		{"environment overrides the file", `, "tx_max_retries": 5`, map[string]string{"VACCINATION_TX_MAX_RETRIES": "1"}, func(c Config) bool {
+			return c.TX_MAX_RETRIES == 1
+		}},
+		{"file overrides the default", `, "port": 9090, "log_level": "Debug"`, nil, func(c Config) bool {
+			return c.PORT == 9090 && c.LOG_LEVEL == "Debug"
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{}
+			if err := load(writeFile(t, "config.json", baseJSON+tt.file+"}"), &cfg, env(tt.vars)); err != nil {
+				t.Fatalf("load: %v", err)
+			}
+			if !tt.check(cfg) {
+				t.Errorf("load config = %+v", cfg)
+			}
+		})
+	}
+}
+
+func TestLoadRejectsUnknownFields(t *testing.T) {
+	cfg := Config{}
+	err := load(writeFile(t, "config.json", baseJSON+`, "db_nmae": "typo"}`), &cfg, env(nil))
+	if err == nil || !strings.Contains(err.Error(), "db_nmae") {
+		t.Errorf("load error = %v, want the unknown field", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(*Config)
+		err    string
+	}{
+		{"valid", func(c *Config) {}, ""},
+		{"missing required fields", func(c *Config) { c.DB_NAME = ""; c.PII_KEYS = nil }, "db_name, pii_keys required"},
+		{"port", func(c *Config) { c.PORT = 70000 }, "port 70000 is not valid"},
+		{"pool size", func(c *Config) { c.Max_Connection_Pool_Size = 0 }, "max_connection_pool_size"},
+		{"zero retries", func(c *Config) { c.TX_MAX_RETRIES = 0 }, ""},
+		{"negative retries", func(c *Config) { c.TX_MAX_RETRIES = -1 }, "tx_max_retries"},
+		{"retry delays", func(c *Config) { c.TX_RETRY_MAX_DELAY_MS = 1 }, "tx_retry_base_delay_ms"},
+		{"booking window", func(c *Config) { c.BOOKING_WINDOW_DAYS = 0 }, "booking_window_days"},
+		{"max beneficiaries", func(c *Config) { c.MAX_BENEFICIARIES_PER_ACCOUNT = 0 }, "max_beneficiaries_per_account"},
+		{"otp expiry", func(c *Config) { c.OTP_EXPIRY_MINUTES = 0 }, "otp_expiry_minutes"},
+		{"zero resend interval", func(c *Config) { c.OTP_RESEND_INTERVAL_SECONDS = 0 }, ""},
+		{"negative resend interval", func(c *Config) { c.OTP_RESEND_INTERVAL_SECONDS = -1 }, "otp_resend_interval_seconds"},
+		{"token ttl", func(c *Config) { c.REFRESH_TOKEN_TTL_HOURS = 0 }, "refresh_token_ttl_hours"},
+		{"slot quota above daily quota", func(c *Config) {
+			c.DEFAULT_DAILY_QUOTA = 10
+			c.DEFAULT_SLOT_QUOTAS = map[string]int{"10:00-11:00": 11}
+		}, "default_slot_quotas[10:00-11:00]"},
+		{"route timeout key", func(c *Config) { c.ROUTE_QUERY_TIMEOUTS = map[string]int{"/appointments": 100} }, "route_query_timeouts[/appointments]"},
+		{"log level", func(c *Config) { c.LOG_LEVEL = "Verbose" }, "log_level Verbose"},
+		{"jwt keys outside dev mode", func(c *Config) { c.DEV_MODE = false; c.SMS_PROVIDER = "file"; c.SMS_FILE_PATH = "sms.log" }, "jwt_keys are required"},
+		{"jwt keys", func(c *Config) {
+			c.DEV_MODE = false
+			c.JWT_KEYS = []JWTKey{{KID: "1", PrivateKeyFile: "key.pem"}}
+			c.SMS_PROVIDER = "file"
+			c.SMS_FILE_PATH = "sms.log"
+		}, ""},
+		{"stderr sms outside dev mode", func(c *Config) {
+			c.DEV_MODE = false
+			c.JWT_KEYS = []JWTKey{{KID: "1", PrivateKeyFile: "key.pem"}}
+		}, "stderr sms_provider is only allowed with dev_mode"},
+		{"file sms without a path", func(c *Config) { c.SMS_PROVIDER = "file" }, "sms_file_path is required"},
+		{"unknown sms provider", func(c *Config) { c.SMS_PROVIDER = "carrier-pigeon" }, "unknown sms_provider carrier-pigeon"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig(t)
+			tt.change(&cfg)
+			err := cfg.Validate()
+
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("Validate error = %v, want none", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Validate error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	cfg := validConfig(t)
+	cfg.DB_PASSWORD = ""
+	cfg.PII_KEYS = []PIIKey{{Version: 1, Key: "pii-key-1"}, {Version: 2, Key: "pii-key-2"}}
+
+	r := cfg.Redacted()
+
+	if r.OTP_SECRET != redacted || r.PII_HASH_KEY != redacted {
+		t.Errorf("secrets are not redacted: %+v", r)
+	}
+	if r.DB_PASSWORD != "" {
+		t.Errorf("empty secret = %q, want it left empty", r.DB_PASSWORD)
+	}
+	for _, k := range r.PII_KEYS {
+		if k.Key != redacted {
+			t.Errorf("pii key %d = %q, want %s", k.Version, k.Key, redacted)
+		}
+	}
+	if len(r.PII_KEYS) != 2 || r.PII_KEYS[1].Version != 2 || r.DB_NAME != cfg.DB_NAME {
+		t.Errorf("fields which are not secret changed: %+v", r)
+	}
+
+	if cfg.OTP_SECRET != "otp-secret" || cfg.PII_KEYS[0].Key != "pii-key-1" {
+		t.Errorf("Redacted modified the config: %+v", cfg)
+	}
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"vaccinationDrive/conf"
+)
+
+//runConfig runs the config command, check prints the config after the environment
+//overrides and defaults are applied. Parse has already validated it by then.
+func runConfig(args []string) error {
+	if len(args) == 0 || args[0] != "check" {
+		return errors.New("usage: config check")
+	}
+
+	data, err := json.MarshalIndent(conf.Cfg.Redacted(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(string(data))
+	return nil
+}
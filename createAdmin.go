@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"vaccinationDrive/dbcon"
+	"vaccinationDrive/internals/daos"
+	"vaccinationDrive/internals/services/role"
+	"vaccinationDrive/models"
+)
+
+//createAdminCommand grants ADMIN to the account of a phone number, creating the account
+//when the number has not logged in yet. It is how the first admin is made.
+func createAdminCommand(fs *flag.FlagSet) func(args []string) error {
+	phone := fs.String("phone", "", "phone number of the admin")
+
+	return func(args []string) error {
+		req := models.OTPRequest{PhoneNumber: *phone}
+		if errs, err := req.Validate(); err != nil {
+			log.Println("Errors : ", errs)
+			return errors.New("a valid -phone is required")
+		}
+
+		ctx := context.Background()
+		l := newLogger()
+		db := dbcon.Get()
+
+		var account *models.Account
+		err := daos.NewUserData(l, db).RunInTransaction(ctx, func(userDao daos.UserDao) error {
+			var err error
+			account, err = userDao.LockAccount(ctx, req.PhoneNumber)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		access, err := role.NewRoleData(l, db).GrantRole(ctx, account.ID, models.RoleAdmin)
+		if err != nil {
+			return err
+		}
+
+		log.Printf("Account %d of %s has the roles %v", access.AccountID, req.PhoneNumber, access.Roles)
+		return nil
+	}
+}
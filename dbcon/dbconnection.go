@@ -45,7 +45,7 @@ func Close() {
 	err := db.Close()
 
 	if err != nil {
-		log.Printf("Closing DB err", err)
+		log.Printf("Closing DB err: %v", err)
 	}
 	log.Printf("DB closed")
 }
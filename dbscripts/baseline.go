@@ -0,0 +1,85 @@
+package dbscripts
+
+import (
+	"log"
+
+	"github.com/go-pg/pg"
+)
+
+//createTables creates the schema of migration 1. Databases created before migrations
+//already have the users table of the baseline (id, name, dob, age, aadhar_no, phone_number
+//and the timestamps), which CREATE TABLE IF NOT EXISTS leaves as it is, so the columns
+//added since are added to it here. Its aadhar_no is moved by the id_documents migration.
+func createTables(tx *pg.Tx) error {
+	var adopt bool
+	if _, err := tx.QueryOne(pg.Scan(&adopt), `SELECT to_regclass('users') IS NOT NULL`); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(createTablesUp); err != nil {
+		return err
+	}
+
+	if !adopt {
+		return nil
+	}
+	return adoptUsers(tx)
+}
+
+//adoptUsers brings a users table created before migrations up to the columns of createTablesUp
+func adoptUsers(tx *pg.Tx) error {
+	_, err := tx.Exec(`ALTER TABLE users
+		ADD COLUMN IF NOT EXISTS priority_group text,
+		ADD COLUMN IF NOT EXISTS comorbidities jsonb,
+		ADD COLUMN IF NOT EXISTS removed_at timestamptz,
+		ADD COLUMN IF NOT EXISTS account_id bigint`)
+	if err != nil {
+		return err
+	}
+
+	accounts, err := backfillAccounts(tx)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Adopted the existing users table, %d accounts created for its phone numbers", accounts)
+	return nil
+}
+
+//backfillAccounts attaches the users without an account to the account of their phone
+//number, creating one account per phone number, then makes the account required
+func backfillAccounts(tx *pg.Tx) (int, error) {
+	res, err := tx.Exec(`INSERT INTO accounts (phone_number)
+		SELECT DISTINCT phone_number FROM users WHERE account_id IS NULL
+		ON CONFLICT (phone_number) DO NOTHING`)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec(`UPDATE users SET account_id = accounts.id FROM accounts
+		WHERE users.account_id IS NULL AND accounts.phone_number = users.phone_number`)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(`ALTER TABLE users ALTER COLUMN account_id SET NOT NULL`); err != nil {
+		return 0, err
+	}
+
+	var fk bool
+	_, err = tx.QueryOne(pg.Scan(&fk), `SELECT count(*) > 0 FROM pg_constraint
+		WHERE conrelid = 'users'::regclass AND contype = 'f' AND conname = 'users_account_id_fkey'`)
+	if err != nil {
+		return 0, err
+	}
+
+	if !fk {
+		_, err = tx.Exec(`ALTER TABLE users ADD CONSTRAINT users_account_id_fkey
+			FOREIGN KEY (account_id) REFERENCES accounts (id) ON DELETE RESTRICT`)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return res.RowsAffected(), nil
+}
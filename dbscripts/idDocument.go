@@ -0,0 +1,209 @@
+package dbscripts
+
+import (
+	"fmt"
+	"log"
+	"vaccinationDrive/internals/pii"
+	"vaccinationDrive/models"
+	validator "vaccinationDrive/validators"
+
+	"github.com/go-pg/pg"
+)
+
+//migrateIDDocuments moves the Aadhaar numbers of databases created before ID document
+//types into the id_number columns as AADHAAR documents, encrypting the plaintext
+//aadhar_no column of the oldest databases on the way
+func migrateIDDocuments(tx *pg.Tx) error {
+	_, err := tx.Exec(`ALTER TABLE users
+		ADD COLUMN IF NOT EXISTS id_type text,
+		ADD COLUMN IF NOT EXISTS id_number_encrypted text,
+		ADD COLUMN IF NOT EXISTS id_number_hash text,
+		ADD COLUMN IF NOT EXISTS id_number_last4 text`)
+	if err != nil {
+		return err
+	}
+
+	migrated := false
+
+	if ok, err := columnExists(tx, "users", "aadhar_no"); err != nil {
+		return err
+	} else if ok {
+		if err := sealPlaintextAadhaar(tx); err != nil {
+			return err
+		}
+		migrated = true
+	}
+
+	if ok, err := columnExists(tx, "users", "aadhar_encrypted"); err != nil {
+		return err
+	} else if ok {
+		if err := moveEncryptedAadhaar(tx); err != nil {
+			return err
+		}
+		migrated = true
+	}
+
+	if !migrated {
+		return nil
+	}
+
+	_, err = tx.Exec(`ALTER TABLE users
+		ALTER COLUMN id_type SET NOT NULL,
+		ALTER COLUMN id_number_encrypted SET NOT NULL,
+		ALTER COLUMN id_number_hash SET NOT NULL,
+		ALTER COLUMN id_number_last4 SET NOT NULL,
+		ADD CONSTRAINT users_id_type_id_number_hash_key UNIQUE (id_type, id_number_hash)`)
+	return err
+}
+
+//RotateIDNumbers re-encrypts the ID numbers sealed with an older key once the key
+//version is rotated, batch users at a time each in its own transaction. It holds the
+//migration lock so it doesn't race a migration or another rotation, and returns the
+//number of users re-encrypted.
+func RotateIDNumbers(db *pg.DB, batch int) (int, error) {
+	if batch < 1 {
+		return 0, fmt.Errorf("batch must be at least 1")
+	}
+
+	kr, err := pii.Get()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := CheckSchema(db); err != nil {
+		return 0, err
+	}
+
+	rotated := 0
+	err = withMigrationLock(db, func(conn *pg.Conn) error {
+		lastID := int64(0)
+		for {
+			var n, read int
+			err := conn.RunInTransaction(func(tx *pg.Tx) error {
+				var err error
+				n, read, lastID, err = rotateIDNumbers(tx, kr, lastID, batch)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+
+			rotated += n
+			if read < batch {
+				return nil
+			}
+			log.Printf("Re-encrypted %d ID numbers, up to user %d", rotated, lastID)
+		}
+	})
+	return rotated, err
+}
+
+func columnExists(tx *pg.Tx, table, column string) (bool, error) {
+	var c int
+	_, err := tx.QueryOne(pg.Scan(&c), `SELECT count(*) FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = ? AND column_name = ?`, table, column)
+	return c > 0, err
+}
+
+//sealPlaintextAadhaar encrypts the aadhar_no column of databases created before encryption
+func sealPlaintextAadhaar(tx *pg.Tx) error {
+	kr, err := pii.Get()
+	if err != nil {
+		return err
+	}
+
+	var rows []struct {
+		ID       int64
+		AadharNo string
+	}
+
+	if _, err := tx.Query(&rows, `SELECT id, aadhar_no FROM users WHERE id_number_hash IS NULL`); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		aadhaar := validator.NormalizeAadhaar(row.AadharNo)
+		if len(aadhaar) < 4 {
+			return fmt.Errorf("user %d has an invalid aadhar_no", row.ID)
+		}
+
+		enc, err := kr.Encrypt(aadhaar)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(`UPDATE users SET id_type = ?, id_number_encrypted = ?, id_number_hash = ?, id_number_last4 = ? WHERE id = ?`,
+			models.IDTypeAadhaar, enc, kr.Hash(aadhaar), aadhaar[len(aadhaar)-4:], row.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(`ALTER TABLE users DROP COLUMN aadhar_no`); err != nil {
+		return err
+	}
+
+	log.Printf("Encrypted the aadhaar numbers of %d users", len(rows))
+	return nil
+}
+
+//moveEncryptedAadhaar moves the encrypted aadhar columns into the id_number columns,
+//the values are kept as they are since the hash is of the number alone
+func moveEncryptedAadhaar(tx *pg.Tx) error {
+	res, err := tx.Exec(`UPDATE users SET id_type = ?, id_number_encrypted = aadhar_encrypted,
+		id_number_hash = aadhar_hash, id_number_last4 = aadhar_last4
+		WHERE id_number_hash IS NULL`, models.IDTypeAadhaar)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`ALTER TABLE users
+		DROP COLUMN aadhar_encrypted,
+		DROP COLUMN aadhar_hash,
+		DROP COLUMN aadhar_last4`)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Moved the aadhaar numbers of %d users to ID documents", res.RowsAffected())
+	return nil
+}
+
+//rotateIDNumbers re-encrypts the ID numbers of the batch of users after lastID, it
+//returns the number re-encrypted, the number read and the last user ID read
+func rotateIDNumbers(tx *pg.Tx, kr *pii.Keyring, lastID int64, batch int) (int, int, int64, error) {
+	var rows []struct {
+		ID                int64
+		IDNumberEncrypted string
+	}
+
+	_, err := tx.Query(&rows, `SELECT id, id_number_encrypted FROM users WHERE id > ? ORDER BY id LIMIT ? FOR UPDATE`, lastID, batch)
+	if err != nil {
+		return 0, 0, lastID, err
+	}
+
+	rotated := 0
+	for _, row := range rows {
+		lastID = row.ID
+		if kr.IsCurrent(row.IDNumberEncrypted) {
+			continue
+		}
+
+		number, err := kr.Decrypt(row.IDNumberEncrypted)
+		if err != nil {
+			return 0, 0, lastID, fmt.Errorf("user %d: %v", row.ID, err)
+		}
+
+		enc, err := kr.Encrypt(number)
+		if err != nil {
+			return 0, 0, lastID, err
+		}
+
+		if _, err := tx.Exec(`UPDATE users SET id_number_encrypted = ? WHERE id = ?`, enc, row.ID); err != nil {
+			return 0, 0, lastID, err
+		}
+		rotated++
+	}
+
+	return rotated, len(rows), lastID, nil
+}
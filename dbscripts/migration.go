@@ -0,0 +1,308 @@
+package dbscripts
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/go-pg/pg"
+	"github.com/go-pg/pg/orm"
+)
+
+//migrationLockID is the postgres advisory lock held while migrating, so replicas
+//started together don't apply the same migration twice
+const migrationLockID int64 = 7243190215
+
+//Migration is one ordered change to the schema, Up and Down run in a transaction.
+//A migration without Down is irreversible, migrating down stops at it.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      func(tx *pg.Tx) error
+	Down    func(tx *pg.Tx) error
+}
+
+//MigrationState is a migration along with whether it is applied to the database
+type MigrationState struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	Dirty     bool       `json:"dirty"`
+	AppliedAt *time.Time `json:"appliedAt,omitempty"`
+}
+
+//schemaMigration is a row of schema_migrations, a dirty row is a migration that
+//was started but not seen to finish
+type schemaMigration struct {
+	tableName struct{} `sql:"schema_migrations"`
+
+	Version   int64
+	Name      string
+	Dirty     bool
+	AppliedAt time.Time
+}
+
+//sqlMigration builds a migration that runs plain SQL statements
+func sqlMigration(version int64, name, up, down string) Migration {
+	return Migration{
+		Version: version,
+		Name:    name,
+		Up:      execSQL(up),
+		Down:    execSQL(down),
+	}
+}
+
+//execSQL is a migration step that runs plain SQL statements
+func execSQL(query string) func(tx *pg.Tx) error {
+	return func(tx *pg.Tx) error {
+		_, err := tx.Exec(query)
+		return err
+	}
+}
+
+//MigrateUp applies the pending migrations in order
+func MigrateUp(db *pg.DB) error {
+	return withMigrationLock(db, func(conn *pg.Conn) error {
+		applied, err := appliedMigrations(conn)
+		if err != nil {
+			return err
+		}
+		if err := checkDirty(applied); err != nil {
+			return err
+		}
+
+		for _, m := range migrations {
+			if _, ok := applied[m.Version]; ok {
+				continue
+			}
+
+			log.Printf("Applying migration %d %s", m.Version, m.Name)
+			if err := runMigration(conn, m, true, m.Up, func(tx *pg.Tx) error {
+				_, err := tx.Exec(`UPDATE schema_migrations SET dirty = false, applied_at = now() WHERE version = ?`, m.Version)
+				return err
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+//MigrateDown reverts the last steps applied migrations, it refuses to revert an
+//irreversible one
+func MigrateDown(db *pg.DB, steps int) error {
+	if steps < 1 {
+		return errors.New("steps must be at least 1")
+	}
+
+	return withMigrationLock(db, func(conn *pg.Conn) error {
+		applied, err := appliedMigrations(conn)
+		if err != nil {
+			return err
+		}
+		if err := checkDirty(applied); err != nil {
+			return err
+		}
+
+		for i := len(migrations) - 1; i >= 0 && steps > 0; i-- {
+			m := migrations[i]
+			if _, ok := applied[m.Version]; !ok {
+				continue
+			}
+			if m.Down == nil {
+				return fmt.Errorf("migration %d %s cannot be reverted", m.Version, m.Name)
+			}
+
+			log.Printf("Reverting migration %d %s", m.Version, m.Name)
+			if err := runMigration(conn, m, false, m.Down, func(tx *pg.Tx) error {
+				_, err := tx.Exec(`DELETE FROM schema_migrations WHERE version = ?`, m.Version)
+				return err
+			}); err != nil {
+				return err
+			}
+			steps--
+		}
+		return nil
+	})
+}
+
+//ForceMigration records the schema as migrated up to version without running anything,
+//for use once a dirty migration has been repaired by hand
+func ForceMigration(db *pg.DB, version int64) error {
+	if version != 0 && findMigration(version) == nil {
+		return fmt.Errorf("unknown migration version %d", version)
+	}
+
+	return withMigrationLock(db, func(conn *pg.Conn) error {
+		return conn.RunInTransaction(func(tx *pg.Tx) error {
+			if _, err := tx.Exec(`DELETE FROM schema_migrations WHERE version > ?`, version); err != nil {
+				return err
+			}
+
+			for _, m := range migrations {
+				if m.Version > version {
+					break
+				}
+				_, err := tx.Exec(`INSERT INTO schema_migrations (version, name, dirty, applied_at) VALUES (?, ?, false, now())
+					ON CONFLICT (version) DO UPDATE SET dirty = false`, m.Version, m.Name)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+}
+
+//GetMigrationStates lists every known migration with its state in the database
+func GetMigrationStates(db *pg.DB) ([]MigrationState, error) {
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+
+	states := []MigrationState{}
+	for _, m := range migrations {
+		state := MigrationState{Version: m.Version, Name: m.Name}
+		if row, ok := applied[m.Version]; ok {
+			appliedAt := row.AppliedAt
+			state.Applied = !row.Dirty
+			state.Dirty = row.Dirty
+			state.AppliedAt = &appliedAt
+			delete(applied, m.Version)
+		}
+		states = append(states, state)
+	}
+
+	//versions applied by a newer build
+	for _, row := range applied {
+		appliedAt := row.AppliedAt
+		states = append(states, MigrationState{Version: row.Version, Name: row.Name, Applied: !row.Dirty, Dirty: row.Dirty, AppliedAt: &appliedAt})
+	}
+
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].Version < states[j].Version
+	})
+	return states, nil
+}
+
+//CheckSchema returns an error if the schema is dirty or has pending migrations
+func CheckSchema(db *pg.DB) error {
+	states, err := GetMigrationStates(db)
+	if err != nil {
+		return err
+	}
+
+	pending := 0
+	for _, state := range states {
+		if state.Dirty {
+			return fmt.Errorf("schema is dirty at migration %d %s, repair it and run migrate force", state.Version, state.Name)
+		}
+		if !state.Applied {
+			pending++
+		}
+	}
+
+	if pending > 0 {
+		return fmt.Errorf("schema has %d pending migrations, run migrate up", pending)
+	}
+	return nil
+}
+
+//runMigration marks the migration dirty, then runs it along with record in one transaction.
+//The transaction rolls back on failure so the mark is undone again, a dirty row is only
+//left behind if the outcome of the migration is unknown.
+func runMigration(conn *pg.Conn, m Migration, up bool, fn, record func(tx *pg.Tx) error) error {
+	_, err := conn.Exec(`INSERT INTO schema_migrations (version, name, dirty) VALUES (?, ?, true)
+		ON CONFLICT (version) DO UPDATE SET dirty = true`, m.Version, m.Name)
+	if err != nil {
+		return err
+	}
+
+	err = conn.RunInTransaction(func(tx *pg.Tx) error {
+		if err := fn(tx); err != nil {
+			return err
+		}
+		return record(tx)
+	})
+	if err == nil {
+		return nil
+	}
+
+	undo := `UPDATE schema_migrations SET dirty = false WHERE version = ?`
+	if up {
+		undo = `DELETE FROM schema_migrations WHERE version = ?`
+	}
+	if _, uerr := conn.Exec(undo, m.Version); uerr != nil {
+		log.Printf("Unable to clear dirty migration %d, err:%s", m.Version, uerr.Error())
+	}
+	return fmt.Errorf("migration %d %s: %v", m.Version, m.Name, err)
+}
+
+func withMigrationLock(db *pg.DB, fn func(conn *pg.Conn) error) error {
+	conn := db.Conn()
+	defer conn.Close()
+
+	if _, err := conn.Exec(`SELECT pg_advisory_lock(?)`, migrationLockID); err != nil {
+		return err
+	}
+	defer func() {
+		if _, err := conn.Exec(`SELECT pg_advisory_unlock(?)`, migrationLockID); err != nil {
+			log.Printf("Unable to release the migration lock, err:%s", err.Error())
+		}
+	}()
+
+	_, err := conn.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version bigint PRIMARY KEY,
+		name text NOT NULL,
+		dirty boolean NOT NULL DEFAULT false,
+		applied_at timestamptz NOT NULL DEFAULT now())`)
+	if err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+//appliedMigrations reads schema_migrations, a missing table means nothing is applied
+func appliedMigrations(db orm.DB) (map[int64]schemaMigration, error) {
+	var exists bool
+	if _, err := db.Query(pg.Scan(&exists), `SELECT to_regclass('schema_migrations') IS NOT NULL`); err != nil {
+		return nil, err
+	}
+
+	applied := map[int64]schemaMigration{}
+	if !exists {
+		return applied, nil
+	}
+
+	var rows []schemaMigration
+	if _, err := db.Query(&rows, `SELECT version, name, dirty, applied_at FROM schema_migrations ORDER BY version`); err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+func checkDirty(applied map[int64]schemaMigration) error {
+	for _, row := range applied {
+		if row.Dirty {
+			return fmt.Errorf("schema is dirty at migration %d %s, repair it and run migrate force", row.Version, row.Name)
+		}
+	}
+	return nil
+}
+
+func findMigration(version int64) *Migration {
+	for i := range migrations {
+		if migrations[i].Version == version {
+			return &migrations[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package dbscripts
+
+//migrations is the ordered list of schema changes. Append new migrations with the next
+//version, never edit or reorder one that has been released.
+var migrations = []Migration{
+	{Version: 1, Name: "create_tables", Up: createTables, Down: execSQL(createTablesDown)},
+	{Version: 2, Name: "id_documents", Up: migrateIDDocuments}, // irreversible, the aadhar columns it drops can't be brought back
+	sqlMigration(3, "appointment_constraints", appointmentConstraintsUp, appointmentConstraintsDown),
+	sqlMigration(4, "active_id_documents", activeIDDocumentsUp, activeIDDocumentsDown),
+	sqlMigration(5, "unique_names", uniqueNamesUp, uniqueNamesDown),
+	sqlMigration(6, "role_foreign_keys", roleForeignKeysUp, roleForeignKeysDown),
+}
+
+//createTablesUp is the schema as CreateTables last made it
+const createTablesUp = `
+CREATE TABLE IF NOT EXISTS "accounts" ("id" bigserial, "phone_number" text NOT NULL UNIQUE, "created_at" timestamptz DEFAULT now(), "updated_at" timestamptz DEFAULT now(), PRIMARY KEY ("id"));
+
+CREATE TABLE IF NOT EXISTS "otps" ("id" bigserial, "phone_number" text NOT NULL UNIQUE, "code_hash" text NOT NULL, "expires_at" timestamptz NOT NULL, "attempts" bigint NOT NULL DEFAULT 0, "send_count" bigint NOT NULL DEFAULT 0, "window_started_at" timestamptz NOT NULL, "last_sent_at" timestamptz NOT NULL, "verified_at" timestamptz, "created_at" timestamptz DEFAULT now(), "updated_at" timestamptz DEFAULT now(), PRIMARY KEY ("id"));
+
+CREATE TABLE IF NOT EXISTS "sessions" ("id" bigserial, "token_hash" text NOT NULL UNIQUE, "account_id" bigint NOT NULL, "expires_at" timestamptz NOT NULL, "revoked_at" timestamptz, "created_at" timestamptz DEFAULT now(), PRIMARY KEY ("id"), FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE);
+
+CREATE TABLE IF NOT EXISTS "users" ("id" bigserial, "name" text, "dob" text, "age" double precision, "id_type" text NOT NULL, "id_number_encrypted" text NOT NULL, "id_number_hash" text NOT NULL, "id_number_last4" text NOT NULL, "phone_number" text NOT NULL, "account_id" bigint NOT NULL, "priority_group" text, "comorbidities" jsonb, "removed_at" timestamptz, "created_at" timestamptz DEFAULT now(), "updated_at" timestamptz DEFAULT now(), PRIMARY KEY ("id"), UNIQUE ("id_type", "id_number_hash"), FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE RESTRICT);
+
+CREATE TABLE IF NOT EXISTS "vaccine_centers" ("id" bigserial, "name" text NOT NULL, "address" text NOT NULL, "district" text NOT NULL, "pincode" text NOT NULL, "opening_time" text NOT NULL, "closing_time" text NOT NULL, "is_active" boolean NOT NULL, "created_at" timestamptz DEFAULT now(), "updated_at" timestamptz DEFAULT now(), PRIMARY KEY ("id"));
+
+CREATE TABLE IF NOT EXISTS "vaccines" ("id" bigserial, "name" text NOT NULL, "manufacturer" text NOT NULL, "number_of_doses" bigint NOT NULL, "dose_intervals" jsonb, "min_age" bigint NOT NULL, "max_age" bigint NOT NULL, "is_active" boolean NOT NULL, "created_at" timestamptz DEFAULT now(), "updated_at" timestamptz DEFAULT now(), PRIMARY KEY ("id"));
+
+CREATE TABLE IF NOT EXISTS "appointments" ("id" bigserial, "beneficiary_id" bigint, "date" text, "time_slot" text, "slot_starts_at" timestamptz, "dose" bigint NOT NULL, "vaccine_id" bigint NOT NULL, "vaccine_center_id" bigint NOT NULL, "status" text NOT NULL DEFAULT 'BOOKED', "cancel_reason" text, "cancel_remarks" text, "checked_in_at" timestamptz, "vaccinated_at" timestamptz, "no_show_at" timestamptz, "cancelled_at" timestamptz, "created_at" timestamptz, "updated_at" timestamptz, PRIMARY KEY ("id"), FOREIGN KEY ("vaccine_id") REFERENCES "vaccines" ("id") ON DELETE RESTRICT, FOREIGN KEY ("vaccine_center_id") REFERENCES "vaccine_centers" ("id") ON DELETE RESTRICT);
+
+CREATE TABLE IF NOT EXISTS "appointment_reschedules" ("id" bigserial, "appointment_id" bigint NOT NULL, "from_date" text, "from_time_slot" text, "from_vaccine_center_id" bigint, "to_date" text, "to_time_slot" text, "to_vaccine_center_id" bigint, "created_at" timestamptz DEFAULT now(), PRIMARY KEY ("id"), FOREIGN KEY ("appointment_id") REFERENCES "appointments" ("id") ON DELETE CASCADE);
+
+CREATE TABLE IF NOT EXISTS "center_capacities" ("id" bigserial, "vaccine_center_id" bigint NOT NULL, "date" text NOT NULL, "daily_quota" bigint NOT NULL, "slot_quotas" jsonb, "dose_quotas" jsonb, "created_at" timestamptz DEFAULT now(), "updated_at" timestamptz DEFAULT now(), PRIMARY KEY ("id"), UNIQUE ("vaccine_center_id", "date"), FOREIGN KEY ("vaccine_center_id") REFERENCES "vaccine_centers" ("id") ON DELETE CASCADE);
+
+CREATE TABLE IF NOT EXISTS "eligibility_policies" ("id" bigserial, "name" text NOT NULL, "phase" text, "min_age" bigint NOT NULL, "max_age" bigint NOT NULL, "age_reference_date" text, "priority_groups" jsonb, "requires_comorbidity" boolean NOT NULL, "vaccine_ids" jsonb, "valid_from" text, "valid_to" text, "is_active" boolean NOT NULL, "created_at" timestamptz DEFAULT now(), "updated_at" timestamptz DEFAULT now(), PRIMARY KEY ("id"));
+
+CREATE TABLE IF NOT EXISTS "account_roles" ("id" bigserial, "account_id" bigint NOT NULL, "role" text NOT NULL, "created_at" timestamptz DEFAULT now(), PRIMARY KEY ("id"), UNIQUE ("account_id", "role"));
+
+CREATE TABLE IF NOT EXISTS "staff_assignments" ("id" bigserial, "account_id" bigint NOT NULL, "vaccine_center_id" bigint NOT NULL, "created_at" timestamptz DEFAULT now(), PRIMARY KEY ("id"), UNIQUE ("account_id", "vaccine_center_id"));
+`
+
+const createTablesDown = `
+DROP TABLE IF EXISTS "staff_assignments", "account_roles", "eligibility_policies", "center_capacities",
+	"appointment_reschedules", "appointments", "vaccines", "vaccine_centers", "users", "sessions", "otps", "accounts";
+`
+
+//appointmentConstraintsUp makes a dose bookable once per beneficiary, the capacity row lock
+//only serializes bookings of one center and date
+const appointmentConstraintsUp = `
+ALTER TABLE "appointments" ALTER COLUMN "beneficiary_id" SET NOT NULL,
+	ADD CONSTRAINT "appointments_beneficiary_id_fkey" FOREIGN KEY ("beneficiary_id") REFERENCES "users" ("id") ON DELETE RESTRICT;
+
+CREATE UNIQUE INDEX "appointments_beneficiary_id_dose_key" ON "appointments" ("beneficiary_id", "dose")
+	WHERE "status" NOT IN ('CANCELLED', 'NO_SHOW');
+
+CREATE INDEX "appointments_vaccine_center_id_date_time_slot_idx" ON "appointments" ("vaccine_center_id", "date", "time_slot");
+`
+
+const appointmentConstraintsDown = `
+DROP INDEX IF EXISTS "appointments_vaccine_center_id_date_time_slot_idx";
+DROP INDEX IF EXISTS "appointments_beneficiary_id_dose_key";
+ALTER TABLE "appointments" DROP CONSTRAINT IF EXISTS "appointments_beneficiary_id_fkey",
+	ALTER COLUMN "beneficiary_id" DROP NOT NULL;
+`
+
+//activeIDDocumentsUp lets the ID document of a removed member be registered again,
+//the index keeps the constraint's name so the constraint registry still matches it
+const activeIDDocumentsUp = `
+ALTER TABLE "users" DROP CONSTRAINT IF EXISTS "users_id_type_id_number_hash_key";
+CREATE UNIQUE INDEX "users_id_type_id_number_hash_key" ON "users" ("id_type", "id_number_hash") WHERE "removed_at" IS NULL;
+`
+
+const activeIDDocumentsDown = `
+DROP INDEX IF EXISTS "users_id_type_id_number_hash_key";
+ALTER TABLE "users" ADD CONSTRAINT "users_id_type_id_number_hash_key" UNIQUE ("id_type", "id_number_hash");
+`
+
+//uniqueNamesUp backs the name checks of the vaccine and center services, which can't
+//see a concurrent insert, with the same comparison
+const uniqueNamesUp = `
+CREATE UNIQUE INDEX "vaccine_centers_name_district_key" ON "vaccine_centers" (lower(trim("name")), lower(trim("district")));
+CREATE UNIQUE INDEX "vaccines_name_key" ON "vaccines" (lower("name"));
+`
+
+const uniqueNamesDown = `
+DROP INDEX IF EXISTS "vaccines_name_key";
+DROP INDEX IF EXISTS "vaccine_centers_name_district_key";
+`
+
+//roleForeignKeysUp adds the foreign keys the AccountRole and StaffAssignment models
+//declare, the rows left behind by deleted accounts and centers are removed first
+const roleForeignKeysUp = `
+DELETE FROM "account_roles" r WHERE NOT EXISTS (SELECT 1 FROM "accounts" a WHERE a."id" = r."account_id");
+DELETE FROM "staff_assignments" s WHERE NOT EXISTS (SELECT 1 FROM "accounts" a WHERE a."id" = s."account_id")
+	OR NOT EXISTS (SELECT 1 FROM "vaccine_centers" c WHERE c."id" = s."vaccine_center_id");
+
+ALTER TABLE "account_roles"
+	ADD CONSTRAINT "account_roles_account_id_fkey" FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "staff_assignments"
+	ADD CONSTRAINT "staff_assignments_account_id_fkey" FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE,
+	ADD CONSTRAINT "staff_assignments_vaccine_center_id_fkey" FOREIGN KEY ("vaccine_center_id") REFERENCES "vaccine_centers" ("id") ON DELETE CASCADE;
+
+CREATE INDEX "staff_assignments_vaccine_center_id_idx" ON "staff_assignments" ("vaccine_center_id");
+`
+
+const roleForeignKeysDown = `
+DROP INDEX IF EXISTS "staff_assignments_vaccine_center_id_idx";
+ALTER TABLE "staff_assignments" DROP CONSTRAINT IF EXISTS "staff_assignments_vaccine_center_id_fkey",
+	DROP CONSTRAINT IF EXISTS "staff_assignments_account_id_fkey";
+ALTER TABLE "account_roles" DROP CONSTRAINT IF EXISTS "account_roles_account_id_fkey";
+`
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"time"
+	"vaccinationDrive/dbcon"
+	"vaccinationDrive/internals/daos"
+	"vaccinationDrive/internals/services/appointment"
+	"vaccinationDrive/internals/services/vaccineCenter"
+	"vaccinationDrive/models"
+)
+
+const exportUsage = "usage: export appointments -date <DD/MM/YYYY> -center <id> [-out <file>]"
+
+var appointmentExportHeader = []string{
+	"appointment_id", "date", "time_slot", "dose", "status", "vaccine",
+	"beneficiary_id", "beneficiary_name", "id_type", "id_number", "phone_number",
+	"checked_in_at", "vaccinated_at",
+}
+
+//exportCommand writes the appointments of a center on a date as CSV, ID numbers are masked
+func exportCommand(fs *flag.FlagSet) func(args []string) error {
+	date := fs.String("date", "", "appointment date in DD/MM/YYYY")
+	centerID := fs.Int64("center", 0, "vaccine center id")
+	out := fs.String("out", "", "output file, stdout when empty")
+
+	return func(args []string) error {
+		if len(args) == 0 || args[0] != "appointments" {
+			return errors.New(exportUsage)
+		}
+
+		//flags given after the subject
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		if *date == "" || *centerID <= 0 {
+			return errors.New(exportUsage)
+		}
+
+		ctx := context.Background()
+		l := newLogger()
+		db := dbcon.Get()
+
+		if _, err := vaccineCenter.NewVaccineCenterData(l, db).GetVaccineCenter(ctx, *centerID); err != nil {
+			return errors.New("Vaccine center not found")
+		}
+
+		w := io.Writer(os.Stdout)
+		if *out != "" {
+			f, err := os.Create(*out)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			w = f
+		}
+
+		count, err := exportAppointments(ctx, w, appointment.NewAppointmentData(l, db), daos.NewUserData(l, db), *centerID, *date)
+		if err != nil {
+			return err
+		}
+
+		log.Printf("Exported %d appointments of center %d on %s", count, *centerID, *date)
+		return nil
+	}
+}
+
+func exportAppointments(ctx context.Context, w io.Writer, appointmentIns *appointment.AppointmentData, userDao daos.UserDao, centerID int64, date string) (int, error) {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(appointmentExportHeader); err != nil {
+		return 0, err
+	}
+
+	filter := models.AppointmentFilter{
+		VaccineCenterID: centerID,
+		Date:            date,
+		ListParams:      models.ListParams{Page: 1, Limit: models.MaxPageLimit, Sort: models.SortAsc},
+	}
+
+	users := map[int64]*models.User{}
+	count := 0
+	for {
+		res, err := appointmentIns.GetAppointments(ctx, filter)
+		if err != nil {
+			return count, err
+		}
+
+		appointments := res.Data.([]models.Appointment)
+		for _, app := range appointments {
+			user, ok := users[app.BeneficiaryID]
+			if !ok {
+				if user, err = userDao.GetUserByID(ctx, app.BeneficiaryID); err != nil {
+					return count, err
+				}
+				users[app.BeneficiaryID] = user
+			}
+
+			vaccineName := ""
+			if app.Vaccine != nil {
+				vaccineName = app.Vaccine.Name
+			}
+
+			err := cw.Write([]string{
+				strconv.FormatInt(app.ID, 10), app.Date, app.TimeSlot, strconv.Itoa(app.Dose), app.Status, vaccineName,
+				strconv.FormatInt(user.ID, 10), user.Name, user.IDType, user.MaskedIDNumber(), user.PhoneNumber,
+				formatExportTime(app.CheckedInAt), formatExportTime(app.VaccinatedAt),
+			})
+			if err != nil {
+				return count, err
+			}
+			count++
+		}
+
+		if len(appointments) < filter.Limit || count >= res.Total {
+			break
+		}
+		filter.Page++
+	}
+
+	cw.Flush()
+	return count, cw.Error()
+}
+
+func formatExportTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -3,7 +3,6 @@ module vaccinationDrive
 go 1.15
 
 require (
-	github.com/FenixAra/go-util v0.0.0-20191206092236-3fbbd91286b1
 	github.com/go-pg/pg v8.0.7+incompatible
 	github.com/google/uuid v1.3.0 // indirect
 	github.com/jinzhu/inflection v1.0.0 // indirect
@@ -1,19 +1,22 @@
 package daos
 
 import (
-	"github.com/FenixAra/go-util/log"
+	"context"
 
+	"vaccinationDrive/internals/logger"
 	"vaccinationDrive/models"
 
 	"github.com/go-pg/pg"
+	"github.com/go-pg/pg/orm"
 )
 
 type AppointmentObj struct {
-	l      *log.Logger
-	dbConn *pg.DB
+	l      *logger.Logger
+	dbConn orm.DB
 }
 
-func NewAppointmentData(l *log.Logger, dbConn *pg.DB) *AppointmentObj {
+//NewAppointmentData accepts either the connection pool or a transaction
+func NewAppointmentData(l *logger.Logger, dbConn orm.DB) *AppointmentObj {
 	return &AppointmentObj{
 		l:      l,
 		dbConn: dbConn,
@@ -21,71 +24,236 @@ func NewAppointmentData(l *log.Logger, dbConn *pg.DB) *AppointmentObj {
 }
 
 type AppointmentDao interface {
-	SaveAppointment(Appointment models.Appointment) error
-	CheckTimeSlotAvailable(Appointment models.Appointment) bool
-	CheckTotalVacineAvailable(Appointment models.Appointment) bool
-	CheckDoseAvailable(Appointment models.Appointment) bool
-	CheckDaysBetweenDoses(Appointment models.Appointment) (*models.Appointment, error)
-	CheckSlotsBooked(Appointment models.Appointment) bool
-	UpdateAppointment(Appointment models.Appointment) error
+	RunInTransaction(ctx context.Context, fn func(appointmentDao AppointmentDao) error) error
+	LockCapacity(ctx context.Context, centerID int64, date string) (*models.CenterCapacity, error)
+	SaveAppointment(ctx context.Context, Appointment models.Appointment) error
+	GetAppointmentByID(ctx context.Context, ID int64) (*models.Appointment, error)
+	GetAppointments(ctx context.Context, filter models.AppointmentFilter) ([]models.Appointment, int, error)
+	LockAppointment(ctx context.Context, ID int64) (*models.Appointment, error)
+	SaveReschedule(ctx context.Context, reschedule *models.AppointmentReschedule) error
+	CheckTimeSlotAvailable(ctx context.Context, Appointment models.Appointment, quota int) (bool, error)
+	CheckTotalVacineAvailable(ctx context.Context, Appointment models.Appointment, quota int) (bool, error)
+	CheckDoseAvailable(ctx context.Context, Appointment models.Appointment, quota int) (bool, error)
+	GetAdministeredDose(ctx context.Context, beneficiaryID int64, dose int) (*models.Appointment, error)
+	CheckDoseBooked(ctx context.Context, Appointment models.Appointment) (bool, error)
+	CheckSlotsBooked(ctx context.Context, Appointment models.Appointment, limit int) (bool, error)
+	UpdateAppointment(ctx context.Context, Appointment models.Appointment) error
+	CancelAppointment(ctx context.Context, Appointment models.Appointment) error
+	UpdateAppointmentStatus(ctx context.Context, Appointment models.Appointment) error
 }
 
-func (a *AppointmentObj) SaveAppointment(Appointment models.Appointment) error {
+//RunInTransaction calls fn with a dao bound to a new transaction, the transaction
+//is committed when fn returns nil and rolled back otherwise.
+//A dao which is already bound to a transaction reuses it, otherwise the transaction
+//is retried when it fails with a retryable error, see runInTransaction.
+func (a *AppointmentObj) RunInTransaction(ctx context.Context, fn func(appointmentDao AppointmentDao) error) error {
+	db, ok := a.dbConn.(*pg.DB)
+	if !ok {
+		return fn(a)
+	}
+
+	return runInTransaction(db.WithContext(ctx), a.l, "appointment", func(tx *pg.Tx) error {
+		return fn(NewAppointmentData(a.l, tx))
+	})
+}
 
-	err := a.dbConn.Insert(&Appointment)
+//LockCapacity selects the capacity of the center for the day FOR UPDATE,
+//the lock is held until the surrounding transaction ends
+func (a *AppointmentObj) LockCapacity(ctx context.Context, centerID int64, date string) (*models.CenterCapacity, error) {
+	capacity := models.CenterCapacity{}
+
+	err := a.dbConn.ModelContext(ctx, &capacity).
+		Where("vaccine_center_id = ? AND date = ?", centerID, date).
+		For("UPDATE").
+		Select()
 	if err != nil {
-		a.l.Errorf("SaveAppointment Error ", err)
+		a.l.Errorf("LockCapacity Error - %s", err.Error())
+		return nil, err
+	}
+
+	return &capacity, nil
+}
+
+func (a *AppointmentObj) SaveAppointment(ctx context.Context, Appointment models.Appointment) error {
+
+	_, err := a.dbConn.ModelContext(ctx, &Appointment).Insert()
+	if err != nil {
+		a.l.Errorf("SaveAppointment Error - %s", err.Error())
 		return err
 	}
 	return nil
 }
 
-func (a *AppointmentObj) UpdateAppointment(Appointment models.Appointment) error {
-	_, err := a.dbConn.Model(&Appointment).Column("date", "time_slot", "dose", "vaccine_center", "updated_at").Where("id = ? ", Appointment.ID).Returning("*").Update()
+func (a *AppointmentObj) GetAppointmentByID(ctx context.Context, ID int64) (*models.Appointment, error) {
+	appointment := models.Appointment{}
+
+	err := a.dbConn.ModelContext(ctx, &appointment).
+		Relation("VaccineCenter").
+		Relation("Vaccine").
+		Relation("Reschedules", func(q *orm.Query) (*orm.Query, error) {
+			return q.Order("id ASC"), nil
+		}).
+		Where("appointment.id = ?", ID).
+		Select()
+	if err != nil {
+		a.l.Errorf("GetAppointmentByID Error - %s", err.Error())
+		return nil, err
+	}
+
+	return &appointment, nil
+}
+
+//GetAppointments returns a page of appointments matching the filter ordered by slot time along with the total count
+func (a *AppointmentObj) GetAppointments(ctx context.Context, filter models.AppointmentFilter) ([]models.Appointment, int, error) {
+	appointments := []models.Appointment{}
+
+	q := a.dbConn.ModelContext(ctx, &appointments).Relation("VaccineCenter").Relation("Vaccine")
+	if filter.BeneficiaryID > 0 {
+		q = q.Where("appointment.beneficiary_id = ?", filter.BeneficiaryID)
+	}
+	if filter.VaccineCenterID > 0 {
+		q = q.Where("appointment.vaccine_center_id = ?", filter.VaccineCenterID)
+	}
+	if filter.Date != "" {
+		q = q.Where("appointment.date = ?", filter.Date)
+	}
+	if filter.Dose > 0 {
+		q = q.Where("appointment.dose = ?", filter.Dose)
+	}
+	if filter.Status != "" {
+		q = q.Where("appointment.status = ?", filter.Status)
+	}
+
+	order := "ASC"
+	if filter.Sort == models.SortDesc {
+		order = "DESC"
+	}
+
+	total, err := q.OrderExpr("appointment.slot_starts_at " + order).
+		OrderExpr("appointment.id " + order).
+		Limit(filter.Limit).
+		Offset(filter.Offset()).
+		SelectAndCount()
+	if err != nil {
+		a.l.Errorf("GetAppointments Error - %s", err.Error())
+		return nil, 0, err
+	}
+
+	return appointments, total, nil
+}
+
+//LockAppointment selects the appointment FOR UPDATE inside the surrounding transaction
+func (a *AppointmentObj) LockAppointment(ctx context.Context, ID int64) (*models.Appointment, error) {
+	appointment := models.Appointment{}
+
+	err := a.dbConn.ModelContext(ctx, &appointment).Where("id = ?", ID).For("UPDATE").Select()
 	if err != nil {
-		a.l.Errorf("UpdateAppointment Error ", err)
+		a.l.Errorf("LockAppointment Error - %s", err.Error())
+		return nil, err
+	}
+
+	return &appointment, nil
+}
+
+func (a *AppointmentObj) SaveReschedule(ctx context.Context, reschedule *models.AppointmentReschedule) error {
+
+	if _, err := a.dbConn.ModelContext(ctx, reschedule).Insert(); err != nil {
+		a.l.Errorf("SaveReschedule Error - %s", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (a *AppointmentObj) UpdateAppointment(ctx context.Context, Appointment models.Appointment) error {
+	_, err := a.dbConn.ModelContext(ctx, &Appointment).Column("date", "time_slot", "slot_starts_at", "vaccine_center_id", "updated_at").Where("id = ? ", Appointment.ID).Returning("*").Update()
+	if err != nil {
+		a.l.Errorf("UpdateAppointment Error - %s", err.Error())
+		return err
+	}
+	return nil
+}
+
+func (a *AppointmentObj) CancelAppointment(ctx context.Context, Appointment models.Appointment) error {
+	_, err := a.dbConn.ModelContext(ctx, &Appointment).Column("status", "cancel_reason", "cancel_remarks", "cancelled_at", "updated_at").Where("id = ? ", Appointment.ID).Returning("*").Update()
+	if err != nil {
+		a.l.Errorf("CancelAppointment Error - %s", err.Error())
 		return err
 	}
 	return nil
 }
 
-func (a *AppointmentObj) CheckTimeSlotAvailable(Appointment models.Appointment) bool {
+func (a *AppointmentObj) UpdateAppointmentStatus(ctx context.Context, Appointment models.Appointment) error {
+	_, err := a.dbConn.ModelContext(ctx, &Appointment).Column("status", "checked_in_at", "vaccinated_at", "no_show_at", "updated_at").Where("id = ? ", Appointment.ID).Returning("*").Update()
+	if err != nil {
+		a.l.Errorf("UpdateAppointmentStatus Error - %s", err.Error())
+		return err
+	}
+	return nil
+}
 
-	c, _ := a.dbConn.Model(&Appointment).Where("date = ? AND time_slot = ? AND vaccine_center = ? ", Appointment.Date, Appointment.TimeSlot, Appointment.VaccineCenter).Count()
-	if c > 10 {
-		return false
+func (a *AppointmentObj) CheckTimeSlotAvailable(ctx context.Context, Appointment models.Appointment, quota int) (bool, error) {
+	c, err := a.dbConn.ModelContext(ctx, &Appointment).Where("date = ? AND time_slot = ? AND vaccine_center_id = ? AND id != ? AND status != ?", Appointment.Date, Appointment.TimeSlot, Appointment.VaccineCenterID, Appointment.ID, models.AppointmentStatusCancelled).Count()
+	if err != nil {
+		a.l.Errorf("CheckTimeSlotAvailable Error - %s", err.Error())
+		return false, err
 	}
-	return true
+	return c < quota, nil
 }
 
-func (a *AppointmentObj) CheckTotalVacineAvailable(Appointment models.Appointment) bool {
-	c, _ := a.dbConn.Model(&Appointment).Where("date = ? AND vaccine_center = ? ", Appointment.Date, Appointment.VaccineCenter).Count()
-	if c > 30 {
-		return false
+func (a *AppointmentObj) CheckTotalVacineAvailable(ctx context.Context, Appointment models.Appointment, quota int) (bool, error) {
+	c, err := a.dbConn.ModelContext(ctx, &Appointment).Where("date = ? AND vaccine_center_id = ? AND id != ? AND status != ?", Appointment.Date, Appointment.VaccineCenterID, Appointment.ID, models.AppointmentStatusCancelled).Count()
+	if err != nil {
+		a.l.Errorf("CheckTotalVacineAvailable Error - %s", err.Error())
+		return false, err
 	}
-	return true
+	return c < quota, nil
 }
 
-func (a *AppointmentObj) CheckDoseAvailable(Appointment models.Appointment) bool {
-	c, _ := a.dbConn.Model(&Appointment).Where("date = ? AND vaccine_center = ? AND dose = ?", Appointment.Date, Appointment.VaccineCenter, Appointment.Dose).Count()
-	if c > 15 {
-		return false
+func (a *AppointmentObj) CheckDoseAvailable(ctx context.Context, Appointment models.Appointment, quota int) (bool, error) {
+	c, err := a.dbConn.ModelContext(ctx, &Appointment).Where("date = ? AND vaccine_center_id = ? AND dose = ? AND id != ? AND status != ?", Appointment.Date, Appointment.VaccineCenterID, Appointment.Dose, Appointment.ID, models.AppointmentStatusCancelled).Count()
+	if err != nil {
+		a.l.Errorf("CheckDoseAvailable Error - %s", err.Error())
+		return false, err
 	}
-	return true
+	return c < quota, nil
 }
 
-func (a *AppointmentObj) CheckDaysBetweenDoses(Appointment models.Appointment) (*models.Appointment, error) {
-	err := a.dbConn.Model(&Appointment).Where("beneficiary_id = ?", Appointment.BeneficiaryID).Select()
+//GetAdministeredDose returns the administered appointment of the beneficiary for the dose
+func (a *AppointmentObj) GetAdministeredDose(ctx context.Context, beneficiaryID int64, dose int) (*models.Appointment, error) {
+	administered := models.Appointment{}
+	err := a.dbConn.ModelContext(ctx, &administered).
+		Where("beneficiary_id = ? AND dose = ? AND status = ?", beneficiaryID, dose, models.AppointmentStatusVaccinated).
+		Order("vaccinated_at DESC").
+		Limit(1).
+		Select()
 	if err != nil {
 		return nil, err
 	}
-	return &Appointment, nil
+	return &administered, nil
+}
+
+//CheckDoseBooked reports whether the beneficiary already holds another booked or administered appointment for the dose
+func (a *AppointmentObj) CheckDoseBooked(ctx context.Context, Appointment models.Appointment) (bool, error) {
+	c, err := a.dbConn.ModelContext(ctx, &Appointment).
+		Where("beneficiary_id = ? AND dose = ? AND id != ?", Appointment.BeneficiaryID, Appointment.Dose, Appointment.ID).
+		WhereIn("status NOT IN (?)", []string{models.AppointmentStatusCancelled, models.AppointmentStatusNoShow}).
+		Count()
+	if err != nil {
+		a.l.Errorf("CheckDoseBooked Error - %s", err.Error())
+		return false, err
+	}
+	return c > 0, nil
 }
 
-func (a *AppointmentObj) CheckSlotsBooked(Appointment models.Appointment) bool {
-	c, _ := a.dbConn.Model(&Appointment).Where("beneficiary_id = ?", Appointment.BeneficiaryID).Count()
-	if c > 2 {
-		return false
+//CheckSlotsBooked reports whether the beneficiary holds less than limit booked or administered appointments
+func (a *AppointmentObj) CheckSlotsBooked(ctx context.Context, Appointment models.Appointment, limit int) (bool, error) {
+	c, err := a.dbConn.ModelContext(ctx, &Appointment).Where("beneficiary_id = ? AND id != ?", Appointment.BeneficiaryID, Appointment.ID).
+		WhereIn("status NOT IN (?)", []string{models.AppointmentStatusCancelled, models.AppointmentStatusNoShow}).
+		Count()
+	if err != nil {
+		a.l.Errorf("CheckSlotsBooked Error - %s", err.Error())
+		return false, err
 	}
-	return true
+	return c < limit, nil
 }
@@ -0,0 +1,164 @@
+package daos
+
+import (
+	"context"
+
+	"time"
+
+	"vaccinationDrive/internals/logger"
+	"vaccinationDrive/models"
+
+	"github.com/go-pg/pg"
+	"github.com/go-pg/pg/orm"
+)
+
+type AuthObj struct {
+	l      *logger.Logger
+	dbConn orm.DB
+}
+
+//NewAuthData accepts either the connection pool or a transaction
+func NewAuthData(l *logger.Logger, dbConn orm.DB) *AuthObj {
+	return &AuthObj{
+		l:      l,
+		dbConn: dbConn,
+	}
+}
+
+type AuthDao interface {
+	RunInTransaction(ctx context.Context, fn func(authDao AuthDao) error) error
+	LockOTP(ctx context.Context, phoneNumber string) (*models.OTP, error)
+	SaveOTP(ctx context.Context, otp *models.OTP) error
+	SaveSession(ctx context.Context, session *models.Session) error
+	LockActiveSession(ctx context.Context, tokenHash string) (*models.Session, error)
+	GetActiveSessionByID(ctx context.Context, ID int64) (*models.Session, error)
+	RotateSession(ctx context.Context, session *models.Session) error
+	RevokeSession(ctx context.Context, ID int64) error
+	Users() UserDao
+}
+
+//RunInTransaction calls fn with a dao bound to a new transaction, the transaction
+//is committed when fn returns nil and rolled back otherwise.
+//A dao which is already bound to a transaction reuses it, otherwise the transaction
+//is retried when it fails with a retryable error, see runInTransaction.
+func (a *AuthObj) RunInTransaction(ctx context.Context, fn func(authDao AuthDao) error) error {
+	db, ok := a.dbConn.(*pg.DB)
+	if !ok {
+		return fn(a)
+	}
+
+	return runInTransaction(db.WithContext(ctx), a.l, "auth", func(tx *pg.Tx) error {
+		return fn(NewAuthData(a.l, tx))
+	})
+}
+
+//Users returns a user dao on the same connection, so that accounts can be locked
+//in the transaction the auth dao is bound to
+func (a *AuthObj) Users() UserDao {
+	return NewUserData(a.l, a.dbConn)
+}
+
+//LockOTP selects the OTP of the phone number FOR UPDATE, pg.ErrNoRows is returned
+//when no OTP was ever sent to it
+func (a *AuthObj) LockOTP(ctx context.Context, phoneNumber string) (*models.OTP, error) {
+	otp := models.OTP{}
+
+	err := a.dbConn.ModelContext(ctx, &otp).Where("phone_number = ?", phoneNumber).For("UPDATE").Select()
+	if err != nil {
+		if err != pg.ErrNoRows {
+			a.l.Errorf("LockOTP Error - %s", err.Error())
+		}
+		return nil, err
+	}
+
+	return &otp, nil
+}
+
+//SaveOTP inserts the first OTP of the phone number and overwrites it afterwards
+func (a *AuthObj) SaveOTP(ctx context.Context, otp *models.OTP) error {
+	var err error
+
+	if otp.ID == 0 {
+		_, err = a.dbConn.ModelContext(ctx, otp).Insert()
+	} else {
+		otp.UpdatedAt = time.Now().UTC()
+		_, err = a.dbConn.ModelContext(ctx, otp).WherePK().Update()
+	}
+	if err != nil {
+		a.l.Errorf("SaveOTP Error - %s", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (a *AuthObj) SaveSession(ctx context.Context, session *models.Session) error {
+
+	if _, err := a.dbConn.ModelContext(ctx, session).Insert(); err != nil {
+		a.l.Errorf("SaveSession Error - %s", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+//LockActiveSession selects the session of the refresh token FOR UPDATE
+//when it is neither expired nor revoked
+func (a *AuthObj) LockActiveSession(ctx context.Context, tokenHash string) (*models.Session, error) {
+	session := models.Session{}
+
+	err := a.dbConn.ModelContext(ctx, &session).
+		Where("token_hash = ?", tokenHash).
+		Where("revoked_at IS NULL").
+		Where("expires_at > ?", time.Now().UTC()).
+		For("UPDATE").
+		Select()
+	if err != nil {
+		a.l.Errorf("LockActiveSession Error - %s", err.Error())
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+//GetActiveSessionByID returns the session when it is neither expired nor revoked
+func (a *AuthObj) GetActiveSessionByID(ctx context.Context, ID int64) (*models.Session, error) {
+	session := models.Session{}
+
+	err := a.dbConn.ModelContext(ctx, &session).
+		Where("id = ?", ID).
+		Where("revoked_at IS NULL").
+		Where("expires_at > ?", time.Now().UTC()).
+		Select()
+	if err != nil {
+		a.l.Errorf("GetActiveSessionByID Error - %s", err.Error())
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+//RotateSession replaces the refresh token of the session
+func (a *AuthObj) RotateSession(ctx context.Context, session *models.Session) error {
+	_, err := a.dbConn.ModelContext(ctx, session).Column("token_hash", "expires_at").Where("id = ?", session.ID).Update()
+	if err != nil {
+		a.l.Errorf("RotateSession Error - %s", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (a *AuthObj) RevokeSession(ctx context.Context, ID int64) error {
+	_, err := a.dbConn.ModelContext(ctx, &models.Session{}).
+		Set("revoked_at = ?", time.Now().UTC()).
+		Where("id = ?", ID).
+		Where("revoked_at IS NULL").
+		Update()
+	if err != nil {
+		a.l.Errorf("RevokeSession Error - %s", err.Error())
+		return err
+	}
+
+	return nil
+}
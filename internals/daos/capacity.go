@@ -0,0 +1,70 @@
+package daos
+
+import (
+	"context"
+
+	"vaccinationDrive/internals/logger"
+	"vaccinationDrive/models"
+
+	"github.com/go-pg/pg"
+)
+
+type CapacityObj struct {
+	l      *logger.Logger
+	dbConn *pg.DB
+}
+
+func NewCapacityData(l *logger.Logger, dbConn *pg.DB) *CapacityObj {
+	return &CapacityObj{
+		l:      l,
+		dbConn: dbConn,
+	}
+}
+
+type CapacityDao interface {
+	SaveCapacity(ctx context.Context, capacity *models.CenterCapacity) error
+	GetCapacity(ctx context.Context, centerID int64, date string) (*models.CenterCapacity, error)
+	GetCapacities(ctx context.Context, centerID int64) ([]models.CenterCapacity, error)
+}
+
+//SaveCapacity publishes the capacity, republishing a day replaces its quotas
+func (c *CapacityObj) SaveCapacity(ctx context.Context, capacity *models.CenterCapacity) error {
+	_, err := c.dbConn.ModelContext(ctx, capacity).
+		OnConflict("(vaccine_center_id, date) DO UPDATE").
+		Set("daily_quota = EXCLUDED.daily_quota").
+		Set("slot_quotas = EXCLUDED.slot_quotas").
+		Set("dose_quotas = EXCLUDED.dose_quotas").
+		Set("updated_at = EXCLUDED.updated_at").
+		Returning("*").
+		Insert()
+	if err != nil {
+		c.l.Errorf("SaveCapacity Error - %s", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (c *CapacityObj) GetCapacity(ctx context.Context, centerID int64, date string) (*models.CenterCapacity, error) {
+	capacity := models.CenterCapacity{}
+
+	err := c.dbConn.ModelContext(ctx, &capacity).Where("vaccine_center_id = ? AND date = ?", centerID, date).Select()
+	if err != nil {
+		c.l.Errorf("GetCapacity Error - %s", err.Error())
+		return nil, err
+	}
+
+	return &capacity, nil
+}
+
+func (c *CapacityObj) GetCapacities(ctx context.Context, centerID int64) ([]models.CenterCapacity, error) {
+	capacities := []models.CenterCapacity{}
+
+	err := c.dbConn.ModelContext(ctx, &capacities).Where("vaccine_center_id = ?", centerID).Order("id DESC").Select()
+	if err != nil {
+		c.l.Errorf("GetCapacities Error - %s", err.Error())
+		return nil, err
+	}
+
+	return capacities, nil
+}
@@ -0,0 +1,100 @@
+package daos
+
+import (
+	"context"
+
+	"vaccinationDrive/internals/logger"
+	"vaccinationDrive/models"
+
+	"github.com/go-pg/pg"
+)
+
+type EligibilityPolicyObj struct {
+	l      *logger.Logger
+	dbConn *pg.DB
+}
+
+func NewEligibilityPolicyData(l *logger.Logger, dbConn *pg.DB) *EligibilityPolicyObj {
+	return &EligibilityPolicyObj{
+		l:      l,
+		dbConn: dbConn,
+	}
+}
+
+type EligibilityPolicyDao interface {
+	SavePolicy(ctx context.Context, policy *models.EligibilityPolicy) error
+	GetPolicies(ctx context.Context, activeOnly bool) ([]models.EligibilityPolicy, error)
+	GetPolicyByID(ctx context.Context, ID int64) (*models.EligibilityPolicy, error)
+	UpdatePolicy(ctx context.Context, policy *models.EligibilityPolicy) error
+	DeactivatePolicy(ctx context.Context, ID int64) error
+}
+
+func (e *EligibilityPolicyObj) SavePolicy(ctx context.Context, policy *models.EligibilityPolicy) error {
+
+	if _, err := e.dbConn.ModelContext(ctx, policy).Insert(); err != nil {
+		e.l.Errorf("SavePolicy Error - %s", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (e *EligibilityPolicyObj) GetPolicies(ctx context.Context, activeOnly bool) ([]models.EligibilityPolicy, error) {
+	policies := []models.EligibilityPolicy{}
+
+	q := e.dbConn.ModelContext(ctx, &policies)
+	if activeOnly {
+		q = q.Where("is_active = ?", true)
+	}
+
+	if err := q.Order("id ASC").Select(); err != nil {
+		e.l.Errorf("GetPolicies Error - %s", err.Error())
+		return nil, err
+	}
+
+	return policies, nil
+}
+
+func (e *EligibilityPolicyObj) GetPolicyByID(ctx context.Context, ID int64) (*models.EligibilityPolicy, error) {
+	policy := models.EligibilityPolicy{}
+
+	if err := e.dbConn.ModelContext(ctx, &policy).Where("id = ?", ID).Select(); err != nil {
+		e.l.Errorf("GetPolicyByID Error - %s", err.Error())
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+func (e *EligibilityPolicyObj) UpdatePolicy(ctx context.Context, policy *models.EligibilityPolicy) error {
+	_, err := e.dbConn.ModelContext(ctx, policy).
+		Column("name", "phase", "min_age", "max_age", "age_reference_date", "priority_groups",
+			"requires_comorbidity", "vaccine_ids", "valid_from", "valid_to", "updated_at").
+		Where("id = ?", policy.ID).
+		Returning("*").
+		Update()
+	if err != nil {
+		e.l.Errorf("UpdatePolicy Error - %s", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (e *EligibilityPolicyObj) DeactivatePolicy(ctx context.Context, ID int64) error {
+	res, err := e.dbConn.ModelContext(ctx, &models.EligibilityPolicy{}).
+		Set("is_active = ?", false).
+		Set("updated_at = now()").
+		Where("id = ?", ID).
+		Update()
+	if err != nil {
+		e.l.Errorf("DeactivatePolicy Error - %s", err.Error())
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return pg.ErrNoRows
+	}
+
+	return nil
+}
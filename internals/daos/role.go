@@ -0,0 +1,160 @@
+package daos
+
+import (
+	"context"
+
+	"vaccinationDrive/internals/logger"
+	"vaccinationDrive/models"
+
+	"github.com/go-pg/pg"
+	"github.com/go-pg/pg/orm"
+)
+
+type RoleObj struct {
+	l      *logger.Logger
+	dbConn orm.DB
+}
+
+//NewRoleData accepts either the connection pool or a transaction
+func NewRoleData(l *logger.Logger, dbConn orm.DB) *RoleObj {
+	return &RoleObj{
+		l:      l,
+		dbConn: dbConn,
+	}
+}
+
+type RoleDao interface {
+	RunInTransaction(ctx context.Context, fn func(roleDao RoleDao) error) error
+	GetRoles(ctx context.Context, accountID int64) ([]string, error)
+	HasRole(ctx context.Context, accountID int64, role string) (bool, error)
+	GrantRole(ctx context.Context, accountID int64, role string) error
+	RevokeRole(ctx context.Context, accountID int64, role string) error
+	GetAssignedCenterIDs(ctx context.Context, accountID int64) ([]int64, error)
+	GetCenterStaff(ctx context.Context, centerID int64) ([]models.StaffAssignment, error)
+	AssignCenter(ctx context.Context, accountID, centerID int64) error
+	UnassignCenter(ctx context.Context, accountID, centerID int64) error
+	UnassignAllCenters(ctx context.Context, accountID int64) error
+}
+
+//RunInTransaction calls fn with a dao bound to a new transaction, the transaction
+//is committed when fn returns nil and rolled back otherwise.
+//A dao which is already bound to a transaction reuses it, otherwise the transaction
+//is retried when it fails with a retryable error, see runInTransaction.
+func (r *RoleObj) RunInTransaction(ctx context.Context, fn func(roleDao RoleDao) error) error {
+	db, ok := r.dbConn.(*pg.DB)
+	if !ok {
+		return fn(r)
+	}
+
+	return runInTransaction(db.WithContext(ctx), r.l, "role", func(tx *pg.Tx) error {
+		return fn(NewRoleData(r.l, tx))
+	})
+}
+
+func (r *RoleObj) GetRoles(ctx context.Context, accountID int64) ([]string, error) {
+	roles := []string{}
+
+	err := r.dbConn.ModelContext(ctx, &models.AccountRole{}).Column("role").Where("account_id = ?", accountID).Order("role ASC").Select(&roles)
+	if err != nil {
+		r.l.Errorf("GetRoles Error - %s", err.Error())
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+func (r *RoleObj) HasRole(ctx context.Context, accountID int64, role string) (bool, error) {
+	c, err := r.dbConn.ModelContext(ctx, &models.AccountRole{}).Where("account_id = ? AND role = ?", accountID, role).Count()
+	if err != nil {
+		r.l.Errorf("HasRole Error - %s", err.Error())
+		return false, err
+	}
+
+	return c > 0, nil
+}
+
+//GrantRole adds the role to the account, granting a role twice is a no-op
+func (r *RoleObj) GrantRole(ctx context.Context, accountID int64, role string) error {
+	ar := models.AccountRole{AccountID: accountID, Role: role}
+	ar.BeforeInsert()
+
+	if _, err := r.dbConn.ModelContext(ctx, &ar).OnConflict("(account_id, role) DO NOTHING").Insert(); err != nil {
+		r.l.Errorf("GrantRole Error - %s", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (r *RoleObj) RevokeRole(ctx context.Context, accountID int64, role string) error {
+	res, err := r.dbConn.ModelContext(ctx, &models.AccountRole{}).Where("account_id = ? AND role = ?", accountID, role).Delete()
+	if err != nil {
+		r.l.Errorf("RevokeRole Error - %s", err.Error())
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return pg.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *RoleObj) GetAssignedCenterIDs(ctx context.Context, accountID int64) ([]int64, error) {
+	IDs := []int64{}
+
+	err := r.dbConn.ModelContext(ctx, &models.StaffAssignment{}).Column("vaccine_center_id").Where("account_id = ?", accountID).Order("vaccine_center_id ASC").Select(&IDs)
+	if err != nil {
+		r.l.Errorf("GetAssignedCenterIDs Error - %s", err.Error())
+		return nil, err
+	}
+
+	return IDs, nil
+}
+
+func (r *RoleObj) GetCenterStaff(ctx context.Context, centerID int64) ([]models.StaffAssignment, error) {
+	staff := []models.StaffAssignment{}
+
+	if err := r.dbConn.ModelContext(ctx, &staff).Where("vaccine_center_id = ?", centerID).Order("account_id ASC").Select(); err != nil {
+		r.l.Errorf("GetCenterStaff Error - %s", err.Error())
+		return nil, err
+	}
+
+	return staff, nil
+}
+
+//AssignCenter assigns the account to the center, assigning twice is a no-op
+func (r *RoleObj) AssignCenter(ctx context.Context, accountID, centerID int64) error {
+	sa := models.StaffAssignment{AccountID: accountID, VaccineCenterID: centerID}
+	sa.BeforeInsert()
+
+	if _, err := r.dbConn.ModelContext(ctx, &sa).OnConflict("(account_id, vaccine_center_id) DO NOTHING").Insert(); err != nil {
+		r.l.Errorf("AssignCenter Error - %s", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (r *RoleObj) UnassignCenter(ctx context.Context, accountID, centerID int64) error {
+	res, err := r.dbConn.ModelContext(ctx, &models.StaffAssignment{}).Where("account_id = ? AND vaccine_center_id = ?", accountID, centerID).Delete()
+	if err != nil {
+		r.l.Errorf("UnassignCenter Error - %s", err.Error())
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return pg.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *RoleObj) UnassignAllCenters(ctx context.Context, accountID int64) error {
+	if _, err := r.dbConn.ModelContext(ctx, &models.StaffAssignment{}).Where("account_id = ?", accountID).Delete(); err != nil {
+		r.l.Errorf("UnassignAllCenters Error - %s", err.Error())
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package daos
+
+import (
+	"context"
+	"expvar"
+	"math/rand"
+	"time"
+
+	"vaccinationDrive/internals/logger"
+	"vaccinationDrive/internals/settings"
+	"vaccinationDrive/models"
+
+	"github.com/go-pg/pg"
+)
+
+//Transaction retries by dao, published on /debug/vars
+var (
+	txRetries   = expvar.NewMap("db_tx_retries")
+	txExhausted = expvar.NewMap("db_tx_retries_exhausted")
+)
+
+//runInTransaction runs fn in a transaction of db and runs it again, after a jittered
+//exponential backoff, when it fails with an error models.IsRetryable accepts, Eg. a
+//serialization failure, a deadlock or a dropped connection.
+//It gives up after the configured retries, or when the context of db is done or its
+//deadline would pass before the next attempt. fn must not keep state across attempts.
+func runInTransaction(db *pg.DB, l *logger.Logger, name string, fn func(tx *pg.Tx) error) error {
+	return retryTransaction(db.Context(), l, name, func() error {
+		return db.RunInTransaction(fn)
+	})
+}
+
+//retryTransaction calls run until it succeeds or fails with an error which is not retryable,
+//following the retry rules of runInTransaction
+func retryTransaction(ctx context.Context, l *logger.Logger, name string, run func() error) error {
+	s := settings.Get()
+
+	for retry := 0; ; retry++ {
+		err := run()
+		if err == nil || !models.IsRetryable(err) {
+			return err
+		}
+
+		if retry >= s.TxMaxRetries {
+			txExhausted.Add(name, 1)
+			l.Errorf("%s transaction failed after %d retries, err:%s", name, retry, err.Error())
+			return err
+		}
+
+		delay := backoff(retry, s.TxRetryBaseDelayMs, s.TxRetryMaxDelayMs)
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(delay).After(deadline) {
+			txExhausted.Add(name, 1)
+			l.Errorf("%s transaction not retried, deadline is too close, err:%s", name, err.Error())
+			return err
+		}
+
+		txRetries.Add(name, 1)
+		l.Warnf("Retrying %s transaction in %s, retry %d of %d, err:%s", name, delay, retry+1, s.TxMaxRetries, err.Error())
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+//backoff doubles the base delay on each retry up to max, and picks a random delay
+//in its upper half so the transactions that collided don't collide again
+func backoff(retry, baseMs, maxMs int) time.Duration {
+	d := time.Duration(baseMs) * time.Millisecond << uint(retry)
+	if max := time.Duration(maxMs) * time.Millisecond; d > max || d <= 0 {
+		d = max
+	}
+	if d <= 1 {
+		return d
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)))
+}
@@ -0,0 +1,106 @@
+package daos
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"vaccinationDrive/conf"
+	"vaccinationDrive/internals/logger"
+)
+
+//fakePgError carries the SQLSTATE field of a postgres error like pg.Error
+type fakePgError struct {
+	code string
+}
+
+func (e fakePgError) Error() string { return "ERROR #" + e.code }
+
+func (e fakePgError) Field(k byte) string {
+	if k == 'C' {
+		return e.code
+	}
+	return ""
+}
+
+func (e fakePgError) IntegrityViolation() bool { return e.code[:2] == "23" }
+
+var (
+	errSerialization   = fakePgError{"40001"}
+	errDeadlock        = fakePgError{"40P01"}
+	errUniqueViolation = fakePgError{"23505"}
+)
+
+func TestRetryTransaction(t *testing.T) {
+	logger.SetOutput(ioutil.Discard)
+	defer logger.SetOutput(os.Stdout)
+
+	cfg := conf.Cfg
+	defer func() { conf.Cfg = cfg }()
+	conf.Cfg.TX_MAX_RETRIES = 3
+	conf.Cfg.TX_RETRY_BASE_DELAY_MS = 1
+	conf.Cfg.TX_RETRY_MAX_DELAY_MS = 2
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	closeDeadline, cancelDeadline := context.WithTimeout(context.Background(), time.Microsecond)
+	defer cancelDeadline()
+
+	tests := []struct {
+		name  string
+		ctx   context.Context
+		errs  []error
+		calls int
+		err   error
+	}{
+		{"succeeds", context.Background(), []error{nil}, 1, nil},
+		{"not retryable", context.Background(), []error{errUniqueViolation}, 1, errUniqueViolation},
+		{"succeeds on retry", context.Background(), []error{errSerialization, errDeadlock, nil}, 3, nil},
+		{"retries exhausted", context.Background(), []error{errSerialization, errSerialization, errSerialization, errSerialization, nil}, 4, errSerialization},
+		{"canceled", canceled, []error{errSerialization, nil}, 1, errSerialization},
+		{"deadline too close", closeDeadline, []error{errDeadlock, nil}, 1, errDeadlock},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := retryTransaction(tt.ctx, logger.New("Error"), "test", func() error {
+				calls++
+				return tt.errs[calls-1]
+			})
+
+			if err != tt.err {
+				t.Errorf("retryTransaction error = %v, want %v", err, tt.err)
+			}
+			if calls != tt.calls {
+				t.Errorf("retryTransaction ran %d times, want %d", calls, tt.calls)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		retry, baseMs, maxMs int
+		cap                  time.Duration
+	}{
+		{0, 20, 500, 20 * time.Millisecond},
+		{1, 20, 500, 40 * time.Millisecond},
+		{4, 20, 500, 320 * time.Millisecond},
+		{5, 20, 500, 500 * time.Millisecond},
+		{40, 20, 500, 500 * time.Millisecond},
+		{3, 20, 0, 0},
+	}
+
+	for _, tt := range tests {
+		for i := 0; i < 200; i++ {
+			d := backoff(tt.retry, tt.baseMs, tt.maxMs)
+			if d < tt.cap/2 || (tt.cap > 0 && d >= tt.cap) || (tt.cap == 0 && d != 0) {
+				t.Fatalf("backoff(%d, %d, %d) = %s, want within [%s, %s)", tt.retry, tt.baseMs, tt.maxMs, d, tt.cap/2, tt.cap)
+			}
+		}
+	}
+}
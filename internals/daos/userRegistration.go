@@ -1,19 +1,22 @@
 package daos
 
 import (
-	"github.com/FenixAra/go-util/log"
+	"context"
 
+	"vaccinationDrive/internals/logger"
 	"vaccinationDrive/models"
 
 	"github.com/go-pg/pg"
+	"github.com/go-pg/pg/orm"
 )
 
 type UserObj struct {
-	l      *log.Logger
-	dbConn *pg.DB
+	l      *logger.Logger
+	dbConn orm.DB
 }
 
-func NewUserData(l *log.Logger, dbConn *pg.DB) *UserObj {
+//NewUserData accepts either the connection pool or a transaction
+func NewUserData(l *logger.Logger, dbConn orm.DB) *UserObj {
 	return &UserObj{
 		l:      l,
 		dbConn: dbConn,
@@ -21,16 +24,142 @@ func NewUserData(l *log.Logger, dbConn *pg.DB) *UserObj {
 }
 
 type UserDao interface {
-	SaveUser(user models.User) error
+	RunInTransaction(ctx context.Context, fn func(userDao UserDao) error) error
+	SaveUser(ctx context.Context, user *models.User) error
+	GetUserByID(ctx context.Context, ID int64) (*models.User, error)
+	IDDocumentExists(ctx context.Context, idType, idNumberHash string) (bool, error)
+	LockAccount(ctx context.Context, phoneNumber string) (*models.Account, error)
+	GetAccountByID(ctx context.Context, ID int64) (*models.Account, error)
+	GetMembers(ctx context.Context, accountID int64) ([]models.User, error)
+	CountMembers(ctx context.Context, accountID int64) (int, error)
+	CountOpenAppointments(ctx context.Context, userID int64) (int, error)
+	RemoveMember(ctx context.Context, user models.User) error
 }
 
-func (u *UserObj) SaveUser(user models.User) error {
+//RunInTransaction calls fn with a dao bound to a new transaction, the transaction
+//is committed when fn returns nil and rolled back otherwise.
+//A dao which is already bound to a transaction reuses it, otherwise the transaction
+//is retried when it fails with a retryable error, see runInTransaction.
+func (u *UserObj) RunInTransaction(ctx context.Context, fn func(userDao UserDao) error) error {
+	db, ok := u.dbConn.(*pg.DB)
+	if !ok {
+		return fn(u)
+	}
+
+	return runInTransaction(db.WithContext(ctx), u.l, "user", func(tx *pg.Tx) error {
+		return fn(NewUserData(u.l, tx))
+	})
+}
 
-	if err := u.dbConn.Insert(&user); err != nil {
-		u.l.Errorf("SaveUser Error ", err)
+func (u *UserObj) SaveUser(ctx context.Context, user *models.User) error {
+
+	if _, err := u.dbConn.ModelContext(ctx, user).Insert(); err != nil {
+		u.l.Errorf("SaveUser Error - %s", err.Error())
 		return err
 	}
 
 	return nil
 
 }
+
+func (u *UserObj) GetUserByID(ctx context.Context, ID int64) (*models.User, error) {
+	user := models.User{}
+
+	if err := u.dbConn.ModelContext(ctx, &user).Where("id = ?", ID).Select(); err != nil {
+		u.l.Errorf("GetUserByID Error - %s", err.Error())
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+//IDDocumentExists reports whether a member which is not removed is registered with the ID document,
+//looked up by its hash
+func (u *UserObj) IDDocumentExists(ctx context.Context, idType, idNumberHash string) (bool, error) {
+	exists, err := u.dbConn.ModelContext(ctx, &models.User{}).Where("id_type = ? AND id_number_hash = ? AND removed_at IS NULL", idType, idNumberHash).Exists()
+	if err != nil {
+		u.l.Errorf("IDDocumentExists Error - %s", err.Error())
+		return false, err
+	}
+
+	return exists, nil
+}
+
+//LockAccount creates the account of the phone number when missing and selects it FOR UPDATE,
+//the lock is held until the surrounding transaction ends
+func (u *UserObj) LockAccount(ctx context.Context, phoneNumber string) (*models.Account, error) {
+	account := models.Account{PhoneNumber: phoneNumber}
+	account.BeforeInsert()
+
+	_, err := u.dbConn.ModelContext(ctx, &account).OnConflict("(phone_number) DO NOTHING").Insert()
+	if err != nil {
+		u.l.Errorf("LockAccount Error - %s", err.Error())
+		return nil, err
+	}
+
+	err = u.dbConn.ModelContext(ctx, &account).Where("phone_number = ?", phoneNumber).For("UPDATE").Select()
+	if err != nil {
+		u.l.Errorf("LockAccount Error - %s", err.Error())
+		return nil, err
+	}
+
+	return &account, nil
+}
+
+func (u *UserObj) GetAccountByID(ctx context.Context, ID int64) (*models.Account, error) {
+	account := models.Account{}
+
+	if err := u.dbConn.ModelContext(ctx, &account).Where("id = ?", ID).Select(); err != nil {
+		u.l.Errorf("GetAccountByID Error - %s", err.Error())
+		return nil, err
+	}
+
+	return &account, nil
+}
+
+//GetMembers returns the beneficiaries of the account which are not removed
+func (u *UserObj) GetMembers(ctx context.Context, accountID int64) ([]models.User, error) {
+	members := []models.User{}
+
+	err := u.dbConn.ModelContext(ctx, &members).Where("account_id = ? AND removed_at IS NULL", accountID).Order("id ASC").Select()
+	if err != nil {
+		u.l.Errorf("GetMembers Error - %s", err.Error())
+		return nil, err
+	}
+
+	return members, nil
+}
+
+func (u *UserObj) CountMembers(ctx context.Context, accountID int64) (int, error) {
+	c, err := u.dbConn.ModelContext(ctx, &models.User{}).Where("account_id = ? AND removed_at IS NULL", accountID).Count()
+	if err != nil {
+		u.l.Errorf("CountMembers Error - %s", err.Error())
+		return 0, err
+	}
+
+	return c, nil
+}
+
+//CountOpenAppointments counts the appointments of the user which are yet to be attended
+func (u *UserObj) CountOpenAppointments(ctx context.Context, userID int64) (int, error) {
+	c, err := u.dbConn.ModelContext(ctx, &models.Appointment{}).
+		Where("beneficiary_id = ?", userID).
+		WhereIn("status IN (?)", []string{models.AppointmentStatusBooked, models.AppointmentStatusCheckedIn}).
+		Count()
+	if err != nil {
+		u.l.Errorf("CountOpenAppointments Error - %s", err.Error())
+		return 0, err
+	}
+
+	return c, nil
+}
+
+func (u *UserObj) RemoveMember(ctx context.Context, user models.User) error {
+	_, err := u.dbConn.ModelContext(ctx, &user).Column("removed_at", "updated_at").Where("id = ?", user.ID).Update()
+	if err != nil {
+		u.l.Errorf("RemoveMember Error - %s", err.Error())
+		return err
+	}
+
+	return nil
+}
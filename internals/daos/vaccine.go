@@ -0,0 +1,111 @@
+package daos
+
+import (
+	"context"
+
+	"vaccinationDrive/internals/logger"
+	"vaccinationDrive/models"
+
+	"github.com/go-pg/pg"
+)
+
+type VaccineObj struct {
+	l      *logger.Logger
+	dbConn *pg.DB
+}
+
+func NewVaccineData(l *logger.Logger, dbConn *pg.DB) *VaccineObj {
+	return &VaccineObj{
+		l:      l,
+		dbConn: dbConn,
+	}
+}
+
+type VaccineDao interface {
+	SaveVaccine(ctx context.Context, vaccine *models.Vaccine) error
+	GetVaccines(ctx context.Context, activeOnly bool) ([]models.Vaccine, error)
+	GetVaccineByID(ctx context.Context, ID int64) (*models.Vaccine, error)
+	VaccineNameExists(ctx context.Context, name string, excludeID int64) (bool, error)
+	UpdateVaccine(ctx context.Context, vaccine *models.Vaccine) error
+	DeactivateVaccine(ctx context.Context, ID int64) error
+}
+
+func (v *VaccineObj) SaveVaccine(ctx context.Context, vaccine *models.Vaccine) error {
+
+	if _, err := v.dbConn.ModelContext(ctx, vaccine).Insert(); err != nil {
+		v.l.Errorf("SaveVaccine Error - %s", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (v *VaccineObj) GetVaccines(ctx context.Context, activeOnly bool) ([]models.Vaccine, error) {
+	vaccines := []models.Vaccine{}
+
+	q := v.dbConn.ModelContext(ctx, &vaccines)
+	if activeOnly {
+		q = q.Where("is_active = ?", true)
+	}
+
+	if err := q.Order("name ASC").Select(); err != nil {
+		v.l.Errorf("GetVaccines Error - %s", err.Error())
+		return nil, err
+	}
+
+	return vaccines, nil
+}
+
+func (v *VaccineObj) GetVaccineByID(ctx context.Context, ID int64) (*models.Vaccine, error) {
+	vaccine := models.Vaccine{}
+
+	if err := v.dbConn.ModelContext(ctx, &vaccine).Where("id = ?", ID).Select(); err != nil {
+		v.l.Errorf("GetVaccineByID Error - %s", err.Error())
+		return nil, err
+	}
+
+	return &vaccine, nil
+}
+
+//VaccineNameExists reports whether another vaccine has the name, irrespective of case
+func (v *VaccineObj) VaccineNameExists(ctx context.Context, name string, excludeID int64) (bool, error) {
+	exists, err := v.dbConn.ModelContext(ctx, &models.Vaccine{}).Where("lower(name) = lower(?)", name).Where("id != ?", excludeID).Exists()
+	if err != nil {
+		v.l.Errorf("VaccineNameExists Error - %s", err.Error())
+		return false, err
+	}
+
+	return exists, nil
+}
+
+func (v *VaccineObj) UpdateVaccine(ctx context.Context, vaccine *models.Vaccine) error {
+	_, err := v.dbConn.ModelContext(ctx, vaccine).
+		Column("name", "manufacturer", "number_of_doses", "dose_intervals", "min_age", "max_age", "updated_at").
+		Where("id = ?", vaccine.ID).
+		Returning("*").
+		Update()
+	if err != nil {
+		v.l.Errorf("UpdateVaccine Error - %s", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (v *VaccineObj) DeactivateVaccine(ctx context.Context, ID int64) error {
+	res, err := v.dbConn.ModelContext(ctx, &models.Vaccine{}).
+		Set("is_active = ?", false).
+		Set("updated_at = now()").
+		Where("id = ?", ID).
+		Update()
+	if err != nil {
+		v.l.Errorf("DeactivateVaccine Error - %s", err.Error())
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return pg.ErrNoRows
+	}
+
+	return nil
+}
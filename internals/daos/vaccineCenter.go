@@ -0,0 +1,118 @@
+package daos
+
+import (
+	"context"
+
+	"vaccinationDrive/internals/logger"
+	"vaccinationDrive/models"
+
+	"github.com/go-pg/pg"
+)
+
+type VaccineCenterObj struct {
+	l      *logger.Logger
+	dbConn *pg.DB
+}
+
+func NewVaccineCenterData(l *logger.Logger, dbConn *pg.DB) *VaccineCenterObj {
+	return &VaccineCenterObj{
+		l:      l,
+		dbConn: dbConn,
+	}
+}
+
+type VaccineCenterDao interface {
+	SaveVaccineCenter(ctx context.Context, center *models.VaccineCenter) error
+	GetVaccineCenters(ctx context.Context, district string, activeOnly bool) ([]models.VaccineCenter, error)
+	GetVaccineCenterByID(ctx context.Context, ID int64) (*models.VaccineCenter, error)
+	VaccineCenterNameExists(ctx context.Context, name, district string, excludeID int64) (bool, error)
+	UpdateVaccineCenter(ctx context.Context, center *models.VaccineCenter) error
+	DeactivateVaccineCenter(ctx context.Context, ID int64) error
+}
+
+func (v *VaccineCenterObj) SaveVaccineCenter(ctx context.Context, center *models.VaccineCenter) error {
+
+	if _, err := v.dbConn.ModelContext(ctx, center).Insert(); err != nil {
+		v.l.Errorf("SaveVaccineCenter Error - %s", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (v *VaccineCenterObj) GetVaccineCenters(ctx context.Context, district string, activeOnly bool) ([]models.VaccineCenter, error) {
+	centers := []models.VaccineCenter{}
+
+	q := v.dbConn.ModelContext(ctx, &centers)
+	if district != "" {
+		q = q.Where("lower(district) = lower(?)", district)
+	}
+	if activeOnly {
+		q = q.Where("is_active = ?", true)
+	}
+
+	if err := q.Order("name ASC").Select(); err != nil {
+		v.l.Errorf("GetVaccineCenters Error - %s", err.Error())
+		return nil, err
+	}
+
+	return centers, nil
+}
+
+func (v *VaccineCenterObj) GetVaccineCenterByID(ctx context.Context, ID int64) (*models.VaccineCenter, error) {
+	center := models.VaccineCenter{}
+
+	if err := v.dbConn.ModelContext(ctx, &center).Where("id = ?", ID).Select(); err != nil {
+		v.l.Errorf("GetVaccineCenterByID Error - %s", err.Error())
+		return nil, err
+	}
+
+	return &center, nil
+}
+
+//VaccineCenterNameExists reports whether another center of the district has the name,
+//irrespective of case and spacing
+func (v *VaccineCenterObj) VaccineCenterNameExists(ctx context.Context, name, district string, excludeID int64) (bool, error) {
+	exists, err := v.dbConn.ModelContext(ctx, &models.VaccineCenter{}).
+		Where("lower(trim(name)) = lower(trim(?)) AND lower(trim(district)) = lower(trim(?))", name, district).
+		Where("id != ?", excludeID).
+		Exists()
+	if err != nil {
+		v.l.Errorf("VaccineCenterNameExists Error - %s", err.Error())
+		return false, err
+	}
+
+	return exists, nil
+}
+
+func (v *VaccineCenterObj) UpdateVaccineCenter(ctx context.Context, center *models.VaccineCenter) error {
+	_, err := v.dbConn.ModelContext(ctx, center).
+		Column("name", "address", "district", "pincode", "opening_time", "closing_time", "updated_at").
+		Where("id = ?", center.ID).
+		Returning("*").
+		Update()
+	if err != nil {
+		v.l.Errorf("UpdateVaccineCenter Error - %s", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (v *VaccineCenterObj) DeactivateVaccineCenter(ctx context.Context, ID int64) error {
+	res, err := v.dbConn.ModelContext(ctx, &models.VaccineCenter{}).
+		Set("is_active = ?", false).
+		Set("updated_at = now()").
+		Where("id = ?", ID).
+		Update()
+	if err != nil {
+		v.l.Errorf("DeactivateVaccineCenter Error - %s", err.Error())
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return pg.ErrNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+// Package logger writes structured log lines, one json object per line
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Level int
+
+const (
+	DEBUG Level = iota + 1
+	INFO
+	WARN
+	ERROR
+	FATAL
+)
+
+//levels by the names used in the config, Eg. log_level "Info"
+var levels = map[string]Level{"Debug": DEBUG, "Info": INFO, "Warn": WARN, "Error": ERROR, "Fatal": FATAL}
+
+var levelNames = map[Level]string{DEBUG: "DEBUG", INFO: "INFO", WARN: "WARN", ERROR: "ERROR", FATAL: "FATAL"}
+
+var (
+	mu  sync.Mutex
+	out io.Writer = os.Stdout
+)
+
+//SetOutput sets the writer of every logger, os.Stdout by default
+func SetOutput(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	out = w
+}
+
+type field struct {
+	key   string
+	value interface{}
+}
+
+//Logger writes the lines at or above its level along with its fields, Eg. the request ID.
+//A Logger is never modified once built, With returns a copy.
+type Logger struct {
+	level  Level
+	fields []field
+}
+
+//New returns a logger of the level named as in the config, an unknown name logs at INFO
+func New(level string) *Logger {
+	l, ok := levels[level]
+	if !ok {
+		l = INFO
+	}
+	return &Logger{level: l}
+}
+
+//With returns a copy of the logger which adds the field to its lines
+func (l *Logger) With(key string, value interface{}) *Logger {
+	fields := make([]field, len(l.fields), len(l.fields)+1)
+	copy(fields, l.fields)
+	return &Logger{level: l.level, fields: append(fields, field{key, value})}
+}
+
+func (l *Logger) Debug(v ...interface{}) { l.write(2, DEBUG, sprint(v...)) }
+func (l *Logger) Info(v ...interface{})  { l.write(2, INFO, sprint(v...)) }
+func (l *Logger) Warn(v ...interface{})  { l.write(2, WARN, sprint(v...)) }
+func (l *Logger) Error(v ...interface{}) { l.write(2, ERROR, sprint(v...)) }
+func (l *Logger) Fatal(v ...interface{}) { l.write(2, FATAL, sprint(v...)) }
+
+func (l *Logger) Debugf(format string, v ...interface{}) {
+	l.write(2, DEBUG, fmt.Sprintf(format, v...))
+}
+func (l *Logger) Infof(format string, v ...interface{}) { l.write(2, INFO, fmt.Sprintf(format, v...)) }
+func (l *Logger) Warnf(format string, v ...interface{}) { l.write(2, WARN, fmt.Sprintf(format, v...)) }
+func (l *Logger) Errorf(format string, v ...interface{}) {
+	l.write(2, ERROR, fmt.Sprintf(format, v...))
+}
+func (l *Logger) Fatalf(format string, v ...interface{}) {
+	l.write(2, FATAL, fmt.Sprintf(format, v...))
+}
+
+//sprint joins the values with spaces like fmt.Println
+func sprint(v ...interface{}) string {
+	return strings.TrimSuffix(fmt.Sprintln(v...), "\n")
+}
+
+//write encodes the line with its keys in a fixed order, time, level, msg and caller
+//first, then the fields. The message and the field values are redacted. depth is
+//the number of frames between write and the caller to report, -1 leaves it out.
+func (l *Logger) write(depth int, level Level, msg string) {
+	if level < l.level {
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString(`{"time":`)
+	encode(buf, time.Now().UTC().Format(time.RFC3339Nano))
+	buf.WriteString(`,"level":`)
+	encode(buf, levelNames[level])
+	buf.WriteString(`,"msg":`)
+	encode(buf, Redact(msg))
+
+	if _, file, line, ok := runtime.Caller(depth); depth >= 0 && ok {
+		buf.WriteString(`,"caller":`)
+		encode(buf, fmt.Sprintf("%s:%d", shortPath(file), line))
+	}
+
+	for _, f := range l.fields {
+		buf.WriteString(",")
+		encode(buf, f.key)
+		buf.WriteString(":")
+		encode(buf, redactField(f.key, f.value))
+	}
+	buf.WriteString("}\n")
+
+	mu.Lock()
+	defer mu.Unlock()
+	out.Write(buf.Bytes())
+}
+
+func encode(buf *bytes.Buffer, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprint(v))
+	}
+	buf.Write(data)
+}
+
+//shortPath keeps the package directory and the file name
+func shortPath(file string) string {
+	if i := strings.LastIndex(file, "/"); i > 0 {
+		if j := strings.LastIndex(file[:i], "/"); j >= 0 {
+			return file[j+1:]
+		}
+	}
+	return file
+}
+
+//StdWriter adapts the lines of the standard log package, set with log.SetOutput and
+//log.SetFlags(0), so they are written as json at INFO
+func StdWriter() io.Writer {
+	return stdWriter{}
+}
+
+type stdWriter struct{}
+
+func (stdWriter) Write(p []byte) (int, error) {
+	(&Logger{level: INFO}).write(-1, INFO, strings.TrimSuffix(string(p), "\n"))
+	return len(p), nil
+}
@@ -0,0 +1,104 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	validator "vaccinationDrive/validators"
+)
+
+const redacted = "REDACTED"
+
+// sensitiveKeys are the fields logged as REDACTED whatever their value
+var sensitiveKeys = map[string]bool{
+	"otp":           true,
+	"password":      true,
+	"token":         true,
+	"accesstoken":   true,
+	"refreshtoken":  true,
+	"authorization": true,
+	"idnumber":      true,
+	"aadharno":      true,
+	"phonenumber":   true,
+}
+
+// piiPatterns are replaced wherever they show up in a message, longer numbers first
+// so an Aadhaar number is not taken for a phone number. A match is only replaced
+// when valid is nil or accepts it.
+var piiPatterns = []struct {
+	re    *regexp.Regexp
+	repl  string
+	valid func(string) bool
+}{
+	{regexp.MustCompile(`\beyJ[\w-]+\.[\w-]+\.[\w-]+`), redacted, nil},                      // jwt
+	{regexp.MustCompile(`(?i)\bbearer\s+[\w.~+/-]+=*`), "Bearer " + redacted, nil},          // authorization header
+	{regexp.MustCompile(`\b[2-9]\d{3}[ -]?\d{4}[ -]?(\d{4})\b`), "XXXX-XXXX-$1", isAadhaar}, // aadhaar
+	{regexp.MustCompile(`(\+91[ -]?)?\b[6-9]\d{5}(\d{4})\b`), "XXXXXX$2", nil},              // mobile number
+	{regexp.MustCompile(`\b[A-Z]{5}\d{4}[A-Z]\b`), redacted, nil},                           // pan
+	{regexp.MustCompile(`\b[A-Z]{3}\d{7}\b`), redacted, nil},                                // voter id
+	{regexp.MustCompile(`\b[A-Z]\d{7}\b`), redacted, nil},                                   // passport
+	{regexp.MustCompile(`\b[A-Z]{2}[ -]?\d{2}[ -]?\d{4}[ -]?\d{7}\b`), redacted, nil},       // driving licence
+}
+
+// isAadhaar keeps other 12 digit numbers, Eg. order IDs or timestamps in ms,
+// out of the Aadhaar redaction by checking the Verhoeff digit
+func isAadhaar(s string) bool {
+	return validator.Aadhaar(s, "") == nil
+}
+
+// Redact masks the phone, ID numbers and tokens found in s
+func Redact(s string) string {
+	for _, p := range piiPatterns {
+		if p.valid == nil {
+			s = p.re.ReplaceAllString(s, p.repl)
+			continue
+		}
+
+		re, repl, valid := p.re, p.repl, p.valid
+		s = re.ReplaceAllStringFunc(s, func(m string) string {
+			if !valid(m) {
+				return m
+			}
+			return re.ReplaceAllString(m, repl)
+		})
+	}
+	return s
+}
+
+// redactField masks the value of a sensitive key, and the PII found in the text of
+// any other value. Lists and structs are redacted in their json form.
+func redactField(key string, value interface{}) interface{} {
+	if sensitiveKeys[strings.ToLower(key)] {
+		return redacted
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return Redact(v)
+	case error:
+		return Redact(v.Error())
+	case fmt.Stringer:
+		return Redact(v.String())
+	}
+
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return value
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return Redact(fmt.Sprint(value))
+	}
+	if r := Redact(string(data)); json.Valid([]byte(r)) {
+		return json.RawMessage(r)
+	}
+	return Redact(string(data))
+}
@@ -0,0 +1,74 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"aadhaar", "Aadhaar 499118665246 registered", "Aadhaar XXXX-XXXX-5246 registered"},
+		{"grouped aadhaar", "Aadhaar 4991 1866 5246", "Aadhaar XXXX-XXXX-5246"},
+		{"12 digits with a wrong check digit", "order 499118665247 placed", "order 499118665247 placed"},
+		{"12 digits starting with 1", "PPO 123456789012", "PPO 123456789012"},
+		{"timestamp in ms", "at 1634567890123", "at 1634567890123"},
+		{"mobile number", "OTP sent to 9876543210", "OTP sent to XXXXXX3210"},
+		{"mobile number with country code", "OTP sent to +91 9876543210", "OTP sent to XXXXXX3210"},
+		{"10 digits starting with 5", "ref 5876543210", "ref 5876543210"},
+		{"jwt", "token eyJhbGciOi.eyJzdWIi.c2ln", "token REDACTED"},
+		{"authorization header", "Authorization: Bearer abc.def", "Authorization: Bearer REDACTED"},
+		{"pan", "PAN ABCPE1234F", "PAN REDACTED"},
+		{"voter id", "EPIC ABC1234567", "EPIC REDACTED"},
+		{"passport", "passport J1234567", "passport REDACTED"},
+		{"driving licence", "DL MH12 2011 0012345", "DL REDACTED"},
+		{"nothing to redact", "Request served", "Request served"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Redact(tt.in); got != tt.want {
+				t.Errorf("Redact(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+type phone string
+
+func (p phone) String() string { return string(p) }
+
+func TestRedactField(t *testing.T) {
+	tests := []struct {
+		name  string
+		key   string
+		value interface{}
+		want  interface{}
+	}{
+		{"sensitive key", "phoneNumber", "9876543210", redacted},
+		{"sensitive key in another case", "IDNUMBER", "ABCPE1234F", redacted},
+		{"sensitive key of a number", "otp", 123456, redacted},
+		{"string", "detail", "OTP sent to 9876543210", "OTP sent to XXXXXX3210"},
+		{"error", "error", errors.New("phone 9876543210 is taken"), "phone XXXXXX3210 is taken"},
+		{"stringer", "to", phone("9876543210"), "XXXXXX3210"},
+		{"number", "userId", int64(9876543210), int64(9876543210)},
+		{"bool", "retryable", true, true},
+		{"nil", "error", nil, nil},
+		{"map", "errors", map[string]string{"idNumber": "499118665246 is registered"},
+			json.RawMessage(`{"idNumber":"XXXX-XXXX-5246 is registered"}`)},
+		{"list", "phones", []string{"9876543210"}, json.RawMessage(`["XXXXXX3210"]`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := redactField(tt.key, tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("redactField(%q, %v) = %#v, want %#v", tt.key, tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package logger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+)
+
+//RequestIDHeader carries the request ID, it is read from the request and echoed in the response
+const RequestIDHeader = "X-Request-ID"
+
+//validRequestID limits the IDs taken from clients to what is safe to echo and log
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+//RequestInfo identifies the request in its log lines. It is shared by the handlers of
+//the request, the auth middleware fills UserID once the caller is known.
+type RequestInfo struct {
+	ID     string
+	Route  string
+	UserID int64
+}
+
+type requestKey struct{}
+
+//WithRequest returns a copy of ctx carrying info
+func WithRequest(ctx context.Context, info *RequestInfo) context.Context {
+	return context.WithValue(ctx, requestKey{}, info)
+}
+
+//RequestFrom returns the request info carried by ctx, nil outside of a request
+func RequestFrom(ctx context.Context) *RequestInfo {
+	info, _ := ctx.Value(requestKey{}).(*RequestInfo)
+	return info
+}
+
+//RequestID returns id when it is a valid ID given by the client, a new one otherwise
+func RequestID(id string) string {
+	if validRequestID.MatchString(id) {
+		return id
+	}
+
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+//ForRequest returns a logger of the level which adds the request ID, route and
+//user ID, when known, to its lines
+func ForRequest(level string, info *RequestInfo) *Logger {
+	l := New(level)
+	if info == nil {
+		return l
+	}
+
+	l = l.With("requestId", info.ID).With("route", info.Route)
+	if info.UserID != 0 {
+		l = l.With("userId", info.UserID)
+	}
+	return l
+}
@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRequestID(t *testing.T) {
+	generated := regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+	tests := []struct {
+		name string
+		id   string
+		kept bool
+	}{
+		{"valid", "7f3c9a2e-1b4d-4e8f-9a6b-2c5d8e1f0a3b", true},
+		{"dots and colons", "web:checkout.42", true},
+		{"max length", strings.Repeat("a", 128), true},
+		{"empty", "", false},
+		{"too long", strings.Repeat("a", 129), false},
+		{"spaces", "a b", false},
+		{"log injection", "id\n{\"level\":\"ERROR\"}", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RequestID(tt.id)
+			if tt.kept {
+				if got != tt.id {
+					t.Errorf("RequestID(%q) = %q, want it kept", tt.id, got)
+				}
+				return
+			}
+			if !generated.MatchString(got) {
+				t.Errorf("RequestID(%q) = %q, want a generated ID", tt.id, got)
+			}
+		})
+	}
+
+	if RequestID("") == RequestID("") {
+		t.Error("RequestID generated the same ID twice")
+	}
+}
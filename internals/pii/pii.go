@@ -0,0 +1,193 @@
+// Package pii encrypts personal identifiers at rest and masks them for display
+package pii
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"vaccinationDrive/conf"
+)
+
+var (
+	ErrNotConfigured = errors.New("pii: encryption keys are not configured")
+	ErrUnknownKey    = errors.New("pii: value is encrypted with an unknown key version")
+	ErrMalformed     = errors.New("pii: malformed encrypted value")
+)
+
+//Keyring holds the AES-GCM keys by version and the HMAC key of the lookup hashes
+type Keyring struct {
+	version int
+	keys    map[int]cipher.AEAD
+	hashKey []byte
+}
+
+//NewKeyring decodes the base64 keys, version picks the key encrypting new values
+func NewKeyring(version int, keys []conf.PIIKey, hashKey string) (*Keyring, error) {
+	kr := &Keyring{version: version, keys: map[int]cipher.AEAD{}}
+
+	for _, k := range keys {
+		if _, ok := kr.keys[k.Version]; ok {
+			return nil, fmt.Errorf("pii: duplicate key version %d", k.Version)
+		}
+
+		raw, err := base64.StdEncoding.DecodeString(k.Key)
+		if err != nil || len(raw) != 32 {
+			return nil, fmt.Errorf("pii: key version %d should be 32 bytes encoded in base64", k.Version)
+		}
+
+		block, err := aes.NewCipher(raw)
+		if err != nil {
+			return nil, err
+		}
+
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		kr.keys[k.Version] = aead
+	}
+
+	if _, ok := kr.keys[version]; !ok {
+		return nil, fmt.Errorf("pii: no key found for version %d", version)
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(hashKey)
+	if err != nil || len(raw) < 32 {
+		return nil, errors.New("pii: hash key should be at least 32 bytes encoded in base64")
+	}
+	kr.hashKey = raw
+
+	return kr, nil
+}
+
+//Encrypt seals the value with the current key as v<version>:<base64 nonce and ciphertext>
+func (kr *Keyring) Encrypt(plaintext string) (string, error) {
+	aead := kr.keys[kr.version]
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return fmt.Sprintf("v%d:%s", kr.version, base64.StdEncoding.EncodeToString(sealed)), nil
+}
+
+//Decrypt opens a value sealed with any of the keys
+func (kr *Keyring) Decrypt(value string) (string, error) {
+	version, sealed, err := split(value)
+	if err != nil {
+		return "", err
+	}
+
+	aead, ok := kr.keys[version]
+	if !ok {
+		return "", ErrUnknownKey
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return "", ErrMalformed
+	}
+
+	plain, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return "", ErrMalformed
+	}
+
+	return string(plain), nil
+}
+
+//IsCurrent reports whether the value is sealed with the current key
+func (kr *Keyring) IsCurrent(value string) bool {
+	version, _, err := split(value)
+	return err == nil && version == kr.version
+}
+
+//Hash is the deterministic keyed hash used to look values up without decrypting them
+func (kr *Keyring) Hash(plaintext string) string {
+	mac := hmac.New(sha256.New, kr.hashKey)
+	mac.Write([]byte(plaintext))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func split(value string) (int, []byte, error) {
+	i := strings.Index(value, ":")
+	if i < 2 || value[0] != 'v' {
+		return 0, nil, ErrMalformed
+	}
+
+	version, err := strconv.Atoi(value[1:i])
+	if err != nil {
+		return 0, nil, ErrMalformed
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(value[i+1:])
+	if err != nil {
+		return 0, nil, ErrMalformed
+	}
+
+	return version, sealed, nil
+}
+
+//MaskAadhaar keeps only the last four digits, XXXX-XXXX-1234
+func MaskAadhaar(last4 string) string {
+	if last4 = lastFour(last4); last4 == "" {
+		return ""
+	}
+	return "XXXX-XXXX-" + last4
+}
+
+//Mask keeps only the last four characters of other identifiers, XXXXXX1234
+func Mask(last4 string) string {
+	if last4 = lastFour(last4); last4 == "" {
+		return ""
+	}
+	return "XXXXXX" + last4
+}
+
+func lastFour(s string) string {
+	if len(s) > 4 {
+		return s[len(s)-4:]
+	}
+	return s
+}
+
+var (
+	keyring *Keyring
+	mu      sync.RWMutex
+)
+
+//Init loads the keys of the config
+func Init() error {
+	kr, err := NewKeyring(conf.Cfg.PII_KEY_VERSION, conf.Cfg.PII_KEYS, conf.Cfg.PII_HASH_KEY)
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	keyring = kr
+	return nil
+}
+
+//Get returns the keyring loaded by Init
+func Get() (*Keyring, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if keyring == nil {
+		return nil, ErrNotConfigured
+	}
+	return keyring, nil
+}
@@ -1,12 +1,13 @@
 package appointment
 
 import (
-	"errors"
+	"context"
 	"time"
 
-	"github.com/FenixAra/go-util/log"
-
 	"vaccinationDrive/internals/daos"
+	"vaccinationDrive/internals/logger"
+	"vaccinationDrive/internals/services/eligibility"
+	"vaccinationDrive/internals/settings"
 	"vaccinationDrive/models"
 	"vaccinationDrive/utils"
 
@@ -14,88 +15,543 @@ import (
 )
 
 type AppointmentData struct {
-	dbConn         *pg.DB
-	l              *log.Logger
-	AppointmentDao daos.AppointmentDao
+	dbConn           *pg.DB
+	l                *logger.Logger
+	AppointmentDao   daos.AppointmentDao
+	VaccineCenterDao daos.VaccineCenterDao
+	VaccineDao       daos.VaccineDao
+	UserDao          daos.UserDao
+	PolicyDao        daos.EligibilityPolicyDao
 }
 
-func NewAppointmentData(l *log.Logger, dbConn *pg.DB) *AppointmentData {
+func NewAppointmentData(l *logger.Logger, dbConn *pg.DB) *AppointmentData {
 	return &AppointmentData{
-		l:              l,
-		dbConn:         dbConn,
-		AppointmentDao: daos.NewAppointmentData(l, dbConn),
+		l:                l,
+		dbConn:           dbConn,
+		AppointmentDao:   daos.NewAppointmentData(l, dbConn),
+		VaccineCenterDao: daos.NewVaccineCenterData(l, dbConn),
+		VaccineDao:       daos.NewVaccineData(l, dbConn),
+		UserDao:          daos.NewUserData(l, dbConn),
+		PolicyDao:        daos.NewEligibilityPolicyData(l, dbConn),
 	}
 
 }
 
-func (a *AppointmentData) BookAppointment(app models.Appointment) error {
+//BookAppointment books the appointment for a beneficiary of the caller's account
+func (a *AppointmentData) BookAppointment(ctx context.Context, caller models.Caller, req models.AppointmentRequest) error {
 
-	today := time.Now()
+	app := req.Appointment()
 
-	bookingDate, _ := utils.StringddMMyyyyToDate(app.Date)
+	if err := a.checkBeneficiary(ctx, caller, app.BeneficiaryID); err != nil {
+		return err
+	}
 
-	diff := today.Sub(bookingDate)
+	vaccine, bookingDate, err := a.validateBooking(ctx, &app)
+	if err != nil {
+		return err
+	}
 
-	numofdays := int(diff.Hours() / 24)
+	app.Status = models.AppointmentStatusBooked
 
-	if numofdays > 90 {
-		a.l.Errorf("You cannot book before 90 days")
-		return errors.New("You cannot book before 90 days")
+	err = a.AppointmentDao.RunInTransaction(ctx, func(appointmentDao daos.AppointmentDao) error {
+		return a.reserveAppointment(ctx, appointmentDao, app, vaccine, bookingDate)
+	})
+	if err != nil {
+		return err
 	}
 
-	timeAvailableFlag := a.AppointmentDao.CheckTimeSlotAvailable(app)
+	return nil
 
-	if !timeAvailableFlag {
-		a.l.Errorf("Slots are booked for selected time")
-		return errors.New("Slots are booked for selected time")
+}
+
+//RescheduleAppointment moves the appointment of the caller's beneficiary to the requested date, slot and center.
+//The capacity checks are run against the new slot, the old slot is released since
+//the moved appointment no longer counts against it.
+func (a *AppointmentData) RescheduleAppointment(ctx context.Context, caller models.Caller, ID int64, req models.Appointment) (*models.Appointment, error) {
+
+	existing, err := a.AppointmentDao.GetAppointmentByID(ctx, ID)
+	if err != nil {
+		return nil, models.NotFound("Appointment not found")
+	}
+
+	if err := a.checkBeneficiary(ctx, caller, existing.BeneficiaryID); err != nil {
+		return nil, err
 	}
 
-	totalVacineAvailableFlag := a.AppointmentDao.CheckTotalVacineAvailable(app)
-	doseAvailableFlag := a.AppointmentDao.CheckDoseAvailable(app)
+	if existing.Status != models.AppointmentStatusBooked {
+		return nil, models.Conflict("Only booked appointments can be rescheduled")
+	}
 
-	if !totalVacineAvailableFlag || !doseAvailableFlag {
-		a.l.Errorf("Vaccine are not available for selected day")
-		return errors.New("Vaccine are not available for selected day")
+	app := *existing
+	app.Date = req.Date
+	app.TimeSlot = req.TimeSlot
+	if req.VaccineCenterID > 0 {
+		app.VaccineCenterID = req.VaccineCenterID
 	}
 
-	maxSlot := a.AppointmentDao.CheckSlotsBooked(app)
-	if !maxSlot {
-		a.l.Errorf("you are reached the maximum slots")
-		return errors.New("you are reached the maximum slots")
+	if app.Date == existing.Date && app.TimeSlot == existing.TimeSlot && app.VaccineCenterID == existing.VaccineCenterID {
+		return nil, models.Conflict("Appointment is already booked for selected slot")
 	}
 
-	beneficiary, err := a.AppointmentDao.CheckDaysBetweenDoses(app)
-	if err == nil {
-		benDate, _ := utils.StringddMMyyyyToDate(beneficiary.Date)
+	vaccine, bookingDate, err := a.validateBooking(ctx, &app)
+	if err != nil {
+		return nil, err
+	}
 
-		doseDateDiff := bookingDate.Sub(benDate)
+	err = a.AppointmentDao.RunInTransaction(ctx, func(appointmentDao daos.AppointmentDao) error {
+		return a.moveAppointment(ctx, appointmentDao, app, vaccine, bookingDate)
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		if doseDateDiff < 15 {
-			a.l.Errorf("Book the slot after 15 days")
-			return errors.New("Book the slot after 15 days")
+	return a.AppointmentDao.GetAppointmentByID(ctx, ID)
+}
+
+//CancelAppointment soft cancels the booked appointment of the caller's beneficiary.
+//A cancelled appointment no longer counts against the capacity of its slot.
+func (a *AppointmentData) CancelAppointment(ctx context.Context, caller models.Caller, ID int64, req models.AppointmentCancellation) (*models.Appointment, error) {
+
+	err := a.AppointmentDao.RunInTransaction(ctx, func(appointmentDao daos.AppointmentDao) error {
+
+		app, err := appointmentDao.LockAppointment(ctx, ID)
+		if err != nil {
+			return models.NotFound("Appointment not found")
 		}
+
+		if err := a.checkBeneficiary(ctx, caller, app.BeneficiaryID); err != nil {
+			return err
+		}
+
+		if !models.CanTransition(app.Status, models.AppointmentStatusCancelled) {
+			return models.Conflict("Only booked appointments can be cancelled")
+		}
+
+		now := time.Now()
+		cutoffHours := settings.Get().CancellationCutoffHours
+		cutoff := time.Duration(cutoffHours) * time.Hour
+		if !app.SlotStartsAt.IsZero() && now.Add(cutoff).After(app.SlotStartsAt) {
+			return models.Conflict("Appointment cannot be cancelled within %d hours of the slot", cutoffHours)
+		}
+
+		app.SetStatus(models.AppointmentStatusCancelled, now)
+		app.CancelReason = req.Reason
+		app.CancelRemarks = req.Remarks
+
+		return appointmentDao.CancelAppointment(ctx, *app)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	if beneficiary.ID > 0 {
-		app.UpdatedAt = time.Now()
-		uperr := a.AppointmentDao.UpdateAppointment(app)
-		if uperr != nil {
-			a.l.Errorf("BookAppointment Error : ", uperr)
-			return uperr
+	return a.AppointmentDao.GetAppointmentByID(ctx, ID)
+}
+
+//CheckInAppointment marks the beneficiary as arrived at the center, only on the appointment day
+func (a *AppointmentData) CheckInAppointment(ctx context.Context, caller models.Caller, ID int64) (*models.Appointment, error) {
+	return a.transitionAppointment(ctx, caller, ID, models.AppointmentStatusCheckedIn, func(app *models.Appointment, now time.Time) error {
+		if utils.DateToStringDFddMMyyyy(now.In(utils.IST)) != app.Date {
+			return models.Conflict("Appointment can be checked in only on the appointment date")
 		}
+		return nil
+	})
+}
+
+//VaccinateAppointment records that the dose was administered to the checked in beneficiary
+func (a *AppointmentData) VaccinateAppointment(ctx context.Context, caller models.Caller, ID int64) (*models.Appointment, error) {
+	return a.transitionAppointment(ctx, caller, ID, models.AppointmentStatusVaccinated, nil)
+}
 
+//NoShowAppointment marks the beneficiary as not turned up, only once the slot has started
+func (a *AppointmentData) NoShowAppointment(ctx context.Context, caller models.Caller, ID int64) (*models.Appointment, error) {
+	return a.transitionAppointment(ctx, caller, ID, models.AppointmentStatusNoShow, func(app *models.Appointment, now time.Time) error {
+		if now.Before(app.SlotStartsAt) {
+			return models.Conflict("Appointment can be marked as no-show only after the slot starts")
+		}
 		return nil
+	})
+}
+
+//transitionAppointment moves the appointment to the status when the transition is allowed
+//and check, if given, accepts the appointment. Only staff of the center of the appointment may move it.
+func (a *AppointmentData) transitionAppointment(ctx context.Context, caller models.Caller, ID int64, status string, check func(app *models.Appointment, now time.Time) error) (*models.Appointment, error) {
+
+	err := a.AppointmentDao.RunInTransaction(ctx, func(appointmentDao daos.AppointmentDao) error {
+
+		app, err := appointmentDao.LockAppointment(ctx, ID)
+		if err != nil {
+			return models.NotFound("Appointment not found")
+		}
+
+		if !caller.CanAccessCenter(app.VaccineCenterID) {
+			a.l.With("vaccineCenterId", app.VaccineCenterID).Debugf("Caller is not assigned to the center of the appointment")
+			return models.ErrForbidden
+		}
+
+		if !models.CanTransition(app.Status, status) {
+			return models.Conflict("Appointment cannot move from %s to %s", app.Status, status)
+		}
+
+		now := time.Now()
+		if check != nil {
+			if err := check(app, now); err != nil {
+				return err
+			}
+		}
+
+		app.SetStatus(status, now)
+		return appointmentDao.UpdateAppointmentStatus(ctx, *app)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return a.AppointmentDao.GetAppointmentByID(ctx, ID)
+}
+
+//GetAppointment returns the appointment to the staff of its center or to the account of its beneficiary
+func (a *AppointmentData) GetAppointment(ctx context.Context, caller models.Caller, ID int64) (*models.Appointment, error) {
+
+	app, err := a.AppointmentDao.GetAppointmentByID(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if caller.CanAccessCenter(app.VaccineCenterID) {
+		return app, nil
+	}
+
+	if err := a.checkBeneficiary(ctx, caller, app.BeneficiaryID); err != nil {
+		return nil, err
+	}
+
+	return app, nil
+}
+
+//GetBeneficiaryAppointments returns a page of appointments of the caller's beneficiary
+func (a *AppointmentData) GetBeneficiaryAppointments(ctx context.Context, caller models.Caller, beneficiaryID int64, filter models.AppointmentFilter) (*models.ListResponse, error) {
+
+	if err := a.checkBeneficiary(ctx, caller, beneficiaryID); err != nil {
+		return nil, err
+	}
+
+	filter.BeneficiaryID = beneficiaryID
+	return a.GetAppointments(ctx, filter)
+}
+
+//GetCenterAppointments returns a page of appointments of the center to its staff
+func (a *AppointmentData) GetCenterAppointments(ctx context.Context, caller models.Caller, centerID int64, filter models.AppointmentFilter) (*models.ListResponse, error) {
+
+	if !caller.CanAccessCenter(centerID) {
+		a.l.With("vaccineCenterId", centerID).Debugf("Caller is not assigned to the center")
+		return nil, models.ErrForbidden
+	}
+
+	filter.VaccineCenterID = centerID
+	return a.GetAppointments(ctx, filter)
+}
+
+//GetAppointments returns a page of appointments matching the filter
+func (a *AppointmentData) GetAppointments(ctx context.Context, filter models.AppointmentFilter) (*models.ListResponse, error) {
+
+	if filter.Status != "" && !models.AppointmentStatuses[filter.Status] {
+		return nil, models.BadRequest("Invalid appointment status %s", filter.Status)
+	}
+
+	if filter.Date != "" {
+		if _, err := utils.StringddMMyyyyToDate(filter.Date); err != nil {
+			return nil, models.BadRequest("Date should be in DD/MM/YYYY format")
+		}
+	}
+
+	appointments, total, err := a.AppointmentDao.GetAppointments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	res := models.NewListResponse(appointments, total, filter.ListParams)
+	return &res, nil
+}
+
+//checkBeneficiary verifies that the beneficiary belongs to the account of the caller,
+//removed members are still accepted so that their history stays readable
+func (a *AppointmentData) checkBeneficiary(ctx context.Context, caller models.Caller, beneficiaryID int64) error {
+
+	user, err := a.UserDao.GetUserByID(ctx, beneficiaryID)
+	if err != nil || !caller.CanAccessAccount(user.AccountID) {
+		a.l.With("beneficiaryId", beneficiaryID).Debugf("Beneficiary does not belong to the account of the caller")
+		return models.ErrForbidden
+	}
+
+	return nil
+}
+
+//validateBooking runs the checks which do not depend on the booked counts
+//and returns the vaccine of the appointment along with the booking date, the slot
+//start is set on the appointment
+func (a *AppointmentData) validateBooking(ctx context.Context, app *models.Appointment) (*models.Vaccine, time.Time, error) {
+
+	bookingDate, err := utils.StringddMMyyyyToDate(app.Date)
+	if err != nil {
+		return nil, bookingDate, models.BadRequest("Appointment date should be in dd/MM/yyyy format")
+	}
+
+	if app.SlotStartsAt, err = utils.SlotStartTime(app.Date, app.TimeSlot); err != nil {
+		a.l.With("error", err).Debugf("Start of the time slot is not valid")
+		return nil, bookingDate, models.BadRequest("Appointment date should be in dd/MM/yyyy format")
+	}
+
+	now := time.Now()
+	days := utils.DaysBetween(now, bookingDate)
+	if days < 0 {
+		return nil, bookingDate, models.BadRequest("Appointment date should not be in the past")
+	}
+
+	if days == 0 && !now.Before(app.SlotStartsAt) {
+		return nil, bookingDate, models.BadRequest("Selected time slot has already started")
+	}
+
+	if window := settings.Get().BookingWindowDays; days > window {
+		return nil, bookingDate, models.BadRequest("Appointments can only be booked %d days ahead", window)
+	}
+
+	center, err := a.VaccineCenterDao.GetVaccineCenterByID(ctx, app.VaccineCenterID)
+	if err != nil {
+		return nil, bookingDate, models.NotFound("Vaccine center not found")
+	}
+
+	if !center.IsActive {
+		return nil, bookingDate, models.Conflict("Vaccine center is not active")
+	}
+
+	vaccine, err := a.VaccineDao.GetVaccineByID(ctx, app.VaccineID)
+	if err != nil {
+		return nil, bookingDate, models.NotFound("Vaccine not found")
+	}
+
+	if !vaccine.IsActive {
+		return nil, bookingDate, models.Conflict("Vaccine is not active")
+	}
+
+	if app.Dose < 1 || app.Dose > vaccine.NumberOfDoses {
+		return nil, bookingDate, models.BadRequest("Dose should be between 1 and %d for %s", vaccine.NumberOfDoses, vaccine.Name)
+	}
+
+	if err := a.checkEligibility(ctx, *app, vaccine, bookingDate); err != nil {
+		return nil, bookingDate, err
+	}
+
+	return vaccine, bookingDate, nil
+}
+
+//checkEligibility checks the age range of the vaccine and the active eligibility policies
+//for the beneficiary on the booking date. Without any active policy only the vaccine's
+//age range applies.
+func (a *AppointmentData) checkEligibility(ctx context.Context, app models.Appointment, vaccine *models.Vaccine, bookingDate time.Time) error {
+
+	beneficiary, err := a.UserDao.GetUserByID(ctx, app.BeneficiaryID)
+	if err != nil {
+		return models.NotFound("Beneficiary not found")
+	}
+
+	if !beneficiary.IsMember() {
+		return models.NotEligible("Beneficiary is not a member of any account")
+	}
+
+	dob, err := beneficiary.DateOfBirth()
+	if err != nil {
+		return models.NotEligible("Beneficiary DOB is not valid")
+	}
+
+	age := utils.AgeOn(dob, bookingDate)
+	if age < vaccine.MinAge || (vaccine.MaxAge > 0 && age > vaccine.MaxAge) {
+		return models.NotEligible("%s is not approved for age %d", vaccine.Name, age)
+	}
+
+	policies, err := a.PolicyDao.GetPolicies(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	if len(policies) == 0 {
+		return nil
+	}
+
+	_, ok := eligibility.Evaluate(policies, eligibility.Applicant{
+		DOB:            dob,
+		PriorityGroup:  beneficiary.PriorityGroup,
+		HasComorbidity: len(beneficiary.Comorbidities) > 0,
+		VaccineID:      vaccine.ID,
+		BookingDate:    bookingDate,
+	})
+	if !ok {
+		return models.NotEligible("Beneficiary is not eligible for vaccination on selected day")
+	}
+
+	return nil
+}
+
+//reserveAppointment runs the capacity checks and saves the appointment.
+//appointmentDao must be bound to a transaction, the capacity row lock taken here
+//serializes concurrent bookings of the center for the day until the transaction ends.
+func (a *AppointmentData) reserveAppointment(ctx context.Context, appointmentDao daos.AppointmentDao, app models.Appointment, vaccine *models.Vaccine, bookingDate time.Time) error {
+
+	if err := a.checkCapacity(ctx, appointmentDao, app); err != nil {
+		return err
+	}
+
+	maxSlot, err := appointmentDao.CheckSlotsBooked(ctx, app, vaccine.NumberOfDoses)
+	if err != nil {
+		return err
+	}
+	if !maxSlot {
+		return models.Conflict("you are reached the maximum slots")
+	}
+
+	if err := a.checkDoseSchedule(ctx, appointmentDao, app, vaccine, bookingDate); err != nil {
+		return err
 	}
 
 	app.CreatedAt = time.Now()
 	app.UpdatedAt = time.Now()
 
-	err = a.AppointmentDao.SaveAppointment(app)
+	err = appointmentDao.SaveAppointment(ctx, app)
 	if err != nil {
-		a.l.Errorf("BookAppointment Error : ", err)
 		return err
 	}
 
 	return nil
+}
 
+//moveAppointment runs the capacity checks for the new slot, updates the appointment
+//and records the reschedule. appointmentDao must be bound to a transaction.
+func (a *AppointmentData) moveAppointment(ctx context.Context, appointmentDao daos.AppointmentDao, app models.Appointment, vaccine *models.Vaccine, bookingDate time.Time) error {
+
+	if err := a.checkCapacity(ctx, appointmentDao, app); err != nil {
+		return err
+	}
+
+	current, err := appointmentDao.LockAppointment(ctx, app.ID)
+	if err != nil && err != pg.ErrNoRows {
+		return err
+	}
+	if err != nil {
+		return models.NotFound("Appointment not found")
+	}
+
+	if current.Status != models.AppointmentStatusBooked {
+		return models.Conflict("Only booked appointments can be rescheduled")
+	}
+
+	if err := a.checkDoseSchedule(ctx, appointmentDao, app, vaccine, bookingDate); err != nil {
+		return err
+	}
+
+	app.UpdatedAt = time.Now()
+	if err := appointmentDao.UpdateAppointment(ctx, app); err != nil {
+		return err
+	}
+
+	err = appointmentDao.SaveReschedule(ctx, &models.AppointmentReschedule{
+		AppointmentID:       app.ID,
+		FromDate:            current.Date,
+		FromTimeSlot:        current.TimeSlot,
+		FromVaccineCenterID: current.VaccineCenterID,
+		ToDate:              app.Date,
+		ToTimeSlot:          app.TimeSlot,
+		ToVaccineCenterID:   app.VaccineCenterID,
+		CreatedAt:           app.UpdatedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+//checkCapacity locks the capacity of the center for the day and checks the
+//slot, day and dose quotas. Counts exclude the appointment itself so a
+//rescheduled appointment does not hold on to its old slot.
+func (a *AppointmentData) checkCapacity(ctx context.Context, appointmentDao daos.AppointmentDao, app models.Appointment) error {
+
+	capacity, err := appointmentDao.LockCapacity(ctx, app.VaccineCenterID, app.Date)
+	if err != nil && err != pg.ErrNoRows {
+		return err
+	}
+	if err != nil {
+		return models.NotFound("Vaccination capacity is not published for selected day")
+	}
+
+	slotQuota, ok := capacity.SlotQuota(app.TimeSlot)
+	if !ok {
+		return models.NotFound("Selected time slot is not available")
+	}
+
+	timeAvailableFlag, err := appointmentDao.CheckTimeSlotAvailable(ctx, app, slotQuota)
+	if err != nil {
+		return err
+	}
+
+	if !timeAvailableFlag {
+		return models.SlotFull("Slots are booked for selected time")
+	}
+
+	totalVacineAvailableFlag, err := appointmentDao.CheckTotalVacineAvailable(ctx, app, capacity.DailyQuota)
+	if err != nil {
+		return err
+	}
+
+	doseAvailableFlag, err := appointmentDao.CheckDoseAvailable(ctx, app, capacity.DoseQuota(app.Dose))
+	if err != nil {
+		return err
+	}
+
+	if !totalVacineAvailableFlag || !doseAvailableFlag {
+		return models.SlotFull("Vaccine are not available for selected day")
+	}
+
+	return nil
+}
+
+//checkDoseSchedule checks that the dose is not booked already and, from the second dose on,
+//that the previous dose of the same vaccine was administered and the booking date falls
+//within the vaccine's interval window counted from the administered date
+func (a *AppointmentData) checkDoseSchedule(ctx context.Context, appointmentDao daos.AppointmentDao, app models.Appointment, vaccine *models.Vaccine, bookingDate time.Time) error {
+
+	booked, err := appointmentDao.CheckDoseBooked(ctx, app)
+	if err != nil {
+		return err
+	}
+	if booked {
+		return models.Conflict("Dose %d is already booked", app.Dose)
+	}
+
+	if app.Dose == 1 {
+		return nil
+	}
+
+	previous, err := appointmentDao.GetAdministeredDose(ctx, app.BeneficiaryID, app.Dose-1)
+	if err != nil && err != pg.ErrNoRows {
+		return err
+	}
+	if err != nil || previous.VaccinatedAt == nil {
+		return models.NotEligible("Dose %d is not administered yet", app.Dose-1)
+	}
+
+	if previous.VaccineID != app.VaccineID {
+		return models.NotEligible("Dose %d should be of the same vaccine as dose %d", app.Dose, app.Dose-1)
+	}
+
+	interval, _ := vaccine.DoseInterval(app.Dose)
+	days := utils.DaysBetween(*previous.VaccinatedAt, bookingDate)
+
+	if days < interval.MinDays {
+		return models.DoseIntervalNotMet("Book dose %d at least %d days after dose %d", app.Dose, interval.MinDays, app.Dose-1)
+	}
+
+	if interval.MaxDays > 0 && days > interval.MaxDays {
+		return models.DoseIntervalNotMet("Book dose %d within %d days of dose %d", app.Dose, interval.MaxDays, app.Dose-1)
+	}
+
+	return nil
 }
@@ -0,0 +1,352 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+
+	"vaccinationDrive/conf"
+	"vaccinationDrive/internals/daos"
+	"vaccinationDrive/internals/logger"
+	"vaccinationDrive/internals/sms"
+	"vaccinationDrive/internals/token"
+	"vaccinationDrive/models"
+
+	"github.com/go-pg/pg"
+)
+
+type AuthData struct {
+	dbConn  *pg.DB
+	l       *logger.Logger
+	authDao daos.AuthDao
+	roleDao daos.RoleDao
+	sender  sms.Sender
+}
+
+func NewAuthData(l *logger.Logger, dbConn *pg.DB) *AuthData {
+	return &AuthData{
+		l:       l,
+		dbConn:  dbConn,
+		authDao: daos.NewAuthData(l, dbConn),
+		roleDao: daos.NewRoleData(l, dbConn),
+		sender:  sms.Get(),
+	}
+
+}
+
+//RequestOTP sends a new OTP to the phone number, replacing the previous one.
+//Requests are throttled per phone number by the resend interval and the hourly limit.
+func (a *AuthData) RequestOTP(ctx context.Context, req models.OTPRequest) error {
+
+	var code string
+	expiry := conf.Cfg.OTP_EXPIRY_MINUTES
+
+	err := a.authDao.RunInTransaction(ctx, func(authDao daos.AuthDao) error {
+
+		now := time.Now().UTC()
+
+		otp, err := authDao.LockOTP(ctx, req.PhoneNumber)
+		if err == pg.ErrNoRows {
+			otp = &models.OTP{PhoneNumber: req.PhoneNumber, WindowStartedAt: now}
+			otp.BeforeInsert()
+		} else if err != nil {
+			return err
+		}
+
+		interval := time.Duration(conf.Cfg.OTP_RESEND_INTERVAL_SECONDS) * time.Second
+		if wait := otp.LastSentAt.Add(interval).Sub(now); wait > 0 {
+			return models.TooManyRequests("Please wait %d seconds before requesting another OTP", int(wait.Seconds())+1)
+		}
+
+		if now.Sub(otp.WindowStartedAt) >= time.Hour {
+			otp.WindowStartedAt = now
+			otp.SendCount = 0
+		}
+
+		if limit := conf.Cfg.OTP_MAX_SENDS_PER_HOUR; otp.SendCount >= limit {
+			return models.TooManyRequests("Only %d OTPs can be requested in an hour, please try again later", limit)
+		}
+
+		code, err = generateOTP()
+		if err != nil {
+			return err
+		}
+
+		otp.CodeHash = hashSecret(req.PhoneNumber + ":" + code)
+		otp.ExpiresAt = now.Add(time.Duration(expiry) * time.Minute)
+		otp.Attempts = 0
+		otp.VerifiedAt = nil
+		otp.SendCount++
+		otp.LastSentAt = now
+
+		return authDao.SaveOTP(ctx, otp)
+	})
+	if err != nil {
+		return err
+	}
+
+	//sent after the commit so the otps row is not locked during the call to the provider,
+	//a failed delivery still counts against the limits like a message that never arrives
+	msg := fmt.Sprintf("%s is your OTP to login to %s. It is valid for %d minutes.", code, conf.Cfg.APP_NAME, expiry)
+	if err := a.sender.Send(req.PhoneNumber, msg); err != nil {
+		a.l.With("error", err).Errorf("Unable to send the OTP")
+		return models.Unavailable("Unable to send the OTP, please try again")
+	}
+
+	return nil
+}
+
+//VerifyOTP checks the OTP of the phone number and opens a session for its account,
+//the account is created when the phone number logs in for the first time
+func (a *AuthData) VerifyOTP(ctx context.Context, req models.OTPVerification) (*models.TokenPair, error) {
+
+	var (
+		verifyErr    error
+		session      models.Session
+		refreshToken string
+	)
+
+	err := a.authDao.RunInTransaction(ctx, func(authDao daos.AuthDao) error {
+
+		//reset in case the transaction is retried
+		verifyErr = nil
+
+		otp, err := authDao.LockOTP(ctx, req.PhoneNumber)
+		if err == pg.ErrNoRows {
+			verifyErr = models.Unauthorized("Please request an OTP first")
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		//the attempt is saved even when the OTP is wrong, so the error is not returned here
+		verifyErr = checkOTP(otp, req, time.Now().UTC())
+		if err := authDao.SaveOTP(ctx, otp); err != nil || verifyErr != nil {
+			return err
+		}
+
+		account, err := authDao.Users().LockAccount(ctx, req.PhoneNumber)
+		if err != nil {
+			return err
+		}
+
+		if refreshToken, err = generateToken(); err != nil {
+			return err
+		}
+
+		session = models.Session{
+			TokenHash: hashToken(refreshToken),
+			AccountID: account.ID,
+			ExpiresAt: refreshExpiry(),
+		}
+		session.BeforeInsert()
+
+		return authDao.SaveSession(ctx, &session)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if verifyErr != nil {
+		return nil, verifyErr
+	}
+
+	return a.issueTokens(ctx, session, refreshToken)
+}
+
+//RefreshTokens rotates the refresh token of the session and issues a new access token,
+//the old refresh token cannot be used again
+func (a *AuthData) RefreshTokens(ctx context.Context, req models.RefreshRequest) (*models.TokenPair, error) {
+
+	var (
+		session      *models.Session
+		refreshToken string
+	)
+
+	err := a.authDao.RunInTransaction(ctx, func(authDao daos.AuthDao) error {
+
+		var err error
+		session, err = authDao.LockActiveSession(ctx, hashToken(req.RefreshToken))
+		if err != nil {
+			return models.Unauthorized("Refresh token is invalid or has expired, please login again")
+		}
+
+		if refreshToken, err = generateToken(); err != nil {
+			return err
+		}
+
+		session.TokenHash = hashToken(refreshToken)
+		session.ExpiresAt = refreshExpiry()
+		return authDao.RotateSession(ctx, session)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return a.issueTokens(ctx, *session, refreshToken)
+}
+
+//Authenticate verifies the access token and returns its caller along with its roles,
+//tokens of a revoked session are rejected before they expire
+func (a *AuthData) Authenticate(ctx context.Context, accessToken string) (*models.Caller, error) {
+
+	ks, err := token.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := ks.Verify(accessToken)
+	if err != nil {
+		a.l.With("error", err).Debugf("Access token is invalid or has expired")
+		return nil, models.Unauthorized("Access token is invalid or has expired")
+	}
+
+	session, err := a.authDao.GetActiveSessionByID(ctx, claims.SessionID)
+	if err != nil || strconv.FormatInt(session.AccountID, 10) != claims.Subject {
+		return nil, models.Unauthorized("Session has ended, please login again")
+	}
+
+	caller, err := a.loadCaller(ctx, session.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	caller.SessionID = session.ID
+	return caller, nil
+}
+
+//Logout revokes the session of the caller along with its refresh and access tokens
+func (a *AuthData) Logout(ctx context.Context, caller models.Caller) error {
+	return a.authDao.RevokeSession(ctx, caller.SessionID)
+}
+
+//issueTokens signs an access token for the session
+func (a *AuthData) issueTokens(ctx context.Context, session models.Session, refreshToken string) (*models.TokenPair, error) {
+
+	jti, err := generateToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	expiresAt := now.Add(time.Duration(conf.Cfg.ACCESS_TOKEN_TTL_MINUTES) * time.Minute)
+
+	ks, err := token.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := ks.Sign(token.Claims{
+		Subject:   strconv.FormatInt(session.AccountID, 10),
+		SessionID: session.ID,
+		ID:        jti,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: expiresAt.Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TokenPair{
+		AccessToken:      accessToken,
+		TokenType:        "Bearer",
+		ExpiresAt:        time.Unix(expiresAt.Unix(), 0).UTC(),
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: session.ExpiresAt,
+		AccountID:        session.AccountID,
+	}, nil
+}
+
+//loadCaller reads the granted roles and center assignments of the account,
+//every account is a beneficiary of its own members
+func (a *AuthData) loadCaller(ctx context.Context, accountID int64) (*models.Caller, error) {
+
+	roles, err := a.roleDao.GetRoles(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	caller := models.Caller{
+		AccountID: accountID,
+		Roles:     append(roles, models.RoleBeneficiary),
+	}
+
+	if caller.HasRole(models.RoleCenterStaff) {
+		if caller.CenterIDs, err = a.roleDao.GetAssignedCenterIDs(ctx, accountID); err != nil {
+			return nil, err
+		}
+	}
+
+	return &caller, nil
+}
+
+//checkOTP counts the attempt and marks the OTP verified when the code matches
+func checkOTP(otp *models.OTP, req models.OTPVerification, now time.Time) error {
+
+	if otp.VerifiedAt != nil {
+		return models.Unauthorized("OTP is already used, please request a new one")
+	}
+
+	if now.After(otp.ExpiresAt) {
+		return models.Unauthorized("OTP has expired, please request a new one")
+	}
+
+	if otp.Attempts >= conf.Cfg.OTP_MAX_ATTEMPTS {
+		return models.TooManyRequests("Too many invalid attempts, please request a new OTP")
+	}
+
+	otp.Attempts++
+
+	if !hmac.Equal([]byte(otp.CodeHash), []byte(hashSecret(req.PhoneNumber+":"+req.OTP))) {
+		return models.Unauthorized("Invalid OTP")
+	}
+
+	otp.VerifiedAt = &now
+	return nil
+}
+
+//hashSecret keys the hash with the configured secret so that leaked hashes of
+//six digit OTPs cannot be brute forced offline
+func hashSecret(secret string) string {
+	mac := hmac.New(sha256.New, []byte(conf.Cfg.OTP_SECRET))
+	mac.Write([]byte(secret))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+//hashToken hashes refresh tokens, they are 256 bit random values so a plain hash is
+//enough and they do not depend on the OTP secret
+func hashToken(refreshToken string) string {
+	sum := sha256.Sum256([]byte(refreshToken))
+	return hex.EncodeToString(sum[:])
+}
+
+func generateOTP() (string, error) {
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(models.OTPLength), nil)
+
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%0*d", models.OTPLength, n), nil
+}
+
+func generateToken() (string, error) {
+	b := make([]byte, 32)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func refreshExpiry() time.Time {
+	return time.Now().UTC().Add(time.Duration(conf.Cfg.REFRESH_TOKEN_TTL_HOURS) * time.Hour)
+}
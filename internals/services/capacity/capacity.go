@@ -0,0 +1,60 @@
+package capacity
+
+import (
+	"context"
+	"time"
+
+	"vaccinationDrive/internals/daos"
+	"vaccinationDrive/internals/logger"
+	"vaccinationDrive/models"
+
+	"github.com/go-pg/pg"
+)
+
+type CapacityData struct {
+	dbConn           *pg.DB
+	l                *logger.Logger
+	capacityDao      daos.CapacityDao
+	vaccineCenterDao daos.VaccineCenterDao
+}
+
+func NewCapacityData(l *logger.Logger, dbConn *pg.DB) *CapacityData {
+	return &CapacityData{
+		l:                l,
+		dbConn:           dbConn,
+		capacityDao:      daos.NewCapacityData(l, dbConn),
+		vaccineCenterDao: daos.NewVaccineCenterData(l, dbConn),
+	}
+}
+
+//PublishCapacity creates or replaces the quotas of a center for the given day
+func (c *CapacityData) PublishCapacity(ctx context.Context, capacity models.CenterCapacity) (*models.CenterCapacity, error) {
+
+	if _, err := c.vaccineCenterDao.GetVaccineCenterByID(ctx, capacity.VaccineCenterID); err != nil {
+		return nil, err
+	}
+
+	capacity.BeforeInsert()
+	capacity.UpdatedAt = time.Now().UTC()
+
+	err := c.capacityDao.SaveCapacity(ctx, &capacity)
+	if err != nil {
+		return nil, err
+	}
+
+	return &capacity, nil
+}
+
+//GetCapacities returns the published capacities of a center, optionally for a single day
+func (c *CapacityData) GetCapacities(ctx context.Context, centerID int64, date string) ([]models.CenterCapacity, error) {
+
+	if date != "" {
+		capacity, err := c.capacityDao.GetCapacity(ctx, centerID, date)
+		if err != nil {
+			return nil, err
+		}
+		return []models.CenterCapacity{*capacity}, nil
+	}
+
+	return c.capacityDao.GetCapacities(ctx, centerID)
+}
@@ -0,0 +1,74 @@
+package eligibility
+
+import (
+	"context"
+	"time"
+
+	"vaccinationDrive/internals/daos"
+	"vaccinationDrive/internals/logger"
+	"vaccinationDrive/models"
+
+	"github.com/go-pg/pg"
+)
+
+type EligibilityData struct {
+	dbConn               *pg.DB
+	l                    *logger.Logger
+	eligibilityPolicyDao daos.EligibilityPolicyDao
+}
+
+func NewEligibilityData(l *logger.Logger, dbConn *pg.DB) *EligibilityData {
+	return &EligibilityData{
+		l:                    l,
+		dbConn:               dbConn,
+		eligibilityPolicyDao: daos.NewEligibilityPolicyData(l, dbConn),
+	}
+}
+
+func (e *EligibilityData) CreatePolicy(ctx context.Context, policy models.EligibilityPolicy) (*models.EligibilityPolicy, error) {
+
+	policy.IsActive = true
+	policy.BeforeInsert()
+
+	err := e.eligibilityPolicyDao.SavePolicy(ctx, &policy)
+	if err != nil {
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+func (e *EligibilityData) GetPolicies(ctx context.Context, activeOnly bool) ([]models.EligibilityPolicy, error) {
+	return e.eligibilityPolicyDao.GetPolicies(ctx, activeOnly)
+}
+
+func (e *EligibilityData) GetPolicy(ctx context.Context, ID int64) (*models.EligibilityPolicy, error) {
+	return e.eligibilityPolicyDao.GetPolicyByID(ctx, ID)
+}
+
+func (e *EligibilityData) UpdatePolicy(ctx context.Context, policy models.EligibilityPolicy) (*models.EligibilityPolicy, error) {
+
+	existing, err := e.eligibilityPolicyDao.GetPolicyByID(ctx, policy.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	policy.IsActive = existing.IsActive
+	policy.UpdatedAt = time.Now().UTC()
+	err = e.eligibilityPolicyDao.UpdatePolicy(ctx, &policy)
+	if err != nil {
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+func (e *EligibilityData) DeactivatePolicy(ctx context.Context, ID int64) error {
+
+	err := e.eligibilityPolicyDao.DeactivatePolicy(ctx, ID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
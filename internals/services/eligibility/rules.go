@@ -0,0 +1,83 @@
+package eligibility
+
+import (
+	"time"
+
+	"vaccinationDrive/models"
+	"vaccinationDrive/utils"
+)
+
+//Applicant holds the beneficiary details a policy is evaluated against
+type Applicant struct {
+	DOB            time.Time
+	PriorityGroup  string
+	HasComorbidity bool
+	VaccineID      int64
+	BookingDate    time.Time
+}
+
+//Evaluate returns the first active policy the applicant satisfies, ok is false when none does
+func Evaluate(policies []models.EligibilityPolicy, applicant Applicant) (*models.EligibilityPolicy, bool) {
+	for i := range policies {
+		if matches(policies[i], applicant) {
+			return &policies[i], true
+		}
+	}
+	return nil, false
+}
+
+func matches(policy models.EligibilityPolicy, applicant Applicant) bool {
+	if !policy.IsActive {
+		return false
+	}
+
+	if from, err := utils.StringddMMyyyyToDate(policy.ValidFrom); err == nil && applicant.BookingDate.Before(from) {
+		return false
+	}
+
+	if to, err := utils.StringddMMyyyyToDate(policy.ValidTo); err == nil && applicant.BookingDate.After(to) {
+		return false
+	}
+
+	referenceDate := applicant.BookingDate
+	if ref, err := utils.StringddMMyyyyToDate(policy.AgeReferenceDate); err == nil {
+		referenceDate = ref
+	}
+
+	age := utils.AgeOn(applicant.DOB, referenceDate)
+	if age < policy.MinAge || (policy.MaxAge > 0 && age > policy.MaxAge) {
+		return false
+	}
+
+	if len(policy.PriorityGroups) > 0 && !contains(policy.PriorityGroups, applicant.PriorityGroup) {
+		return false
+	}
+
+	if policy.RequiresComorbidity && !applicant.HasComorbidity {
+		return false
+	}
+
+	if len(policy.VaccineIDs) > 0 {
+		found := false
+		for _, ID := range policy.VaccineIDs {
+			if ID == applicant.VaccineID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package eligibility
+
+import (
+	"testing"
+	"time"
+
+	"vaccinationDrive/models"
+	"vaccinationDrive/utils"
+)
+
+func TestEvaluate(t *testing.T) {
+	date := func(s string) time.Time {
+		d, err := utils.StringddMMyyyyToDate(s)
+		if err != nil {
+			t.Fatalf("invalid date %s: %v", s, err)
+		}
+		return d
+	}
+
+	seniors := models.EligibilityPolicy{ID: 1, Name: "Seniors", MinAge: 60, IsActive: true}
+	workers := models.EligibilityPolicy{ID: 2, Name: "Healthcare workers", IsActive: true,
+		PriorityGroups: []string{models.PriorityGroupHealthcareWorker}}
+	comorbid := models.EligibilityPolicy{ID: 3, Name: "45 plus with comorbidity", MinAge: 45, MaxAge: 59,
+		RequiresComorbidity: true, IsActive: true}
+	phase := models.EligibilityPolicy{ID: 4, Name: "Adults", MinAge: 18, VaccineIDs: []int64{7},
+		AgeReferenceDate: "01/01/2022", ValidFrom: "01/05/2021", ValidTo: "31/12/2021", IsActive: true}
+	inactive := models.EligibilityPolicy{ID: 5, Name: "Everyone", IsActive: false}
+
+	tests := []struct {
+		name      string
+		policies  []models.EligibilityPolicy
+		applicant Applicant
+		policyID  int64
+	}{
+		{"no policies", nil,
+			Applicant{DOB: date("15/08/1960"), BookingDate: date("01/06/2021")}, 0},
+		{"inactive policy", []models.EligibilityPolicy{inactive},
+			Applicant{DOB: date("15/08/1960"), BookingDate: date("01/06/2021")}, 0},
+		{"min age", []models.EligibilityPolicy{seniors},
+			Applicant{DOB: date("15/08/1960"), BookingDate: date("01/06/2021")}, 1},
+		{"below min age", []models.EligibilityPolicy{seniors},
+			Applicant{DOB: date("02/06/1961"), BookingDate: date("01/06/2021")}, 0},
+		{"turns min age on the booking date", []models.EligibilityPolicy{seniors},
+			Applicant{DOB: date("01/06/1961"), BookingDate: date("01/06/2021")}, 1},
+		{"priority group", []models.EligibilityPolicy{seniors, workers},
+			Applicant{DOB: date("01/01/1990"), PriorityGroup: models.PriorityGroupHealthcareWorker, BookingDate: date("01/06/2021")}, 2},
+		{"other priority group", []models.EligibilityPolicy{workers},
+			Applicant{DOB: date("01/01/1990"), PriorityGroup: models.PriorityGroupGeneral, BookingDate: date("01/06/2021")}, 0},
+		{"comorbidity", []models.EligibilityPolicy{comorbid},
+			Applicant{DOB: date("01/01/1970"), HasComorbidity: true, BookingDate: date("01/06/2021")}, 3},
+		{"without comorbidity", []models.EligibilityPolicy{comorbid},
+			Applicant{DOB: date("01/01/1970"), BookingDate: date("01/06/2021")}, 0},
+		{"above max age", []models.EligibilityPolicy{comorbid},
+			Applicant{DOB: date("01/01/1950"), HasComorbidity: true, BookingDate: date("01/06/2021")}, 0},
+		{"first matching policy", []models.EligibilityPolicy{seniors, comorbid},
+			Applicant{DOB: date("01/01/1955"), HasComorbidity: true, BookingDate: date("01/06/2021")}, 1},
+		{"age on the reference date", []models.EligibilityPolicy{phase},
+			Applicant{DOB: date("31/12/2003"), VaccineID: 7, BookingDate: date("01/06/2021")}, 4},
+		{"other vaccine", []models.EligibilityPolicy{phase},
+			Applicant{DOB: date("01/01/1990"), VaccineID: 8, BookingDate: date("01/06/2021")}, 0},
+		{"before valid from", []models.EligibilityPolicy{phase},
+			Applicant{DOB: date("01/01/1990"), VaccineID: 7, BookingDate: date("30/04/2021")}, 0},
+		{"on valid to", []models.EligibilityPolicy{phase},
+			Applicant{DOB: date("01/01/1990"), VaccineID: 7, BookingDate: date("31/12/2021")}, 4},
+		{"after valid to", []models.EligibilityPolicy{phase},
+			Applicant{DOB: date("01/01/1990"), VaccineID: 7, BookingDate: date("01/01/2022")}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, ok := Evaluate(tt.policies, tt.applicant)
+
+			if tt.policyID == 0 {
+				if ok {
+					t.Errorf("Evaluate matched policy %d, want no match", policy.ID)
+				}
+				return
+			}
+
+			if !ok {
+				t.Fatalf("Evaluate matched no policy, want %d", tt.policyID)
+			}
+			if policy.ID != tt.policyID {
+				t.Errorf("Evaluate matched policy %d, want %d", policy.ID, tt.policyID)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package role
+
+import (
+	"context"
+
+	"vaccinationDrive/internals/daos"
+	"vaccinationDrive/internals/logger"
+	"vaccinationDrive/models"
+
+	"github.com/go-pg/pg"
+)
+
+type RoleData struct {
+	dbConn           *pg.DB
+	l                *logger.Logger
+	roleDao          daos.RoleDao
+	userDao          daos.UserDao
+	vaccineCenterDao daos.VaccineCenterDao
+}
+
+func NewRoleData(l *logger.Logger, dbConn *pg.DB) *RoleData {
+	return &RoleData{
+		l:                l,
+		dbConn:           dbConn,
+		roleDao:          daos.NewRoleData(l, dbConn),
+		userDao:          daos.NewUserData(l, dbConn),
+		vaccineCenterDao: daos.NewVaccineCenterData(l, dbConn),
+	}
+}
+
+//GetAccountAccess returns the roles of the account and the centers it is assigned to
+func (r *RoleData) GetAccountAccess(ctx context.Context, accountID int64) (*models.AccountAccess, error) {
+
+	if _, err := r.userDao.GetAccountByID(ctx, accountID); err != nil {
+		return nil, models.NotFound("Account not found")
+	}
+
+	roles, err := r.roleDao.GetRoles(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	centerIDs, err := r.roleDao.GetAssignedCenterIDs(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AccountAccess{
+		AccountID: accountID,
+		Roles:     append(roles, models.RoleBeneficiary),
+		CenterIDs: centerIDs,
+	}, nil
+}
+
+func (r *RoleData) GrantRole(ctx context.Context, accountID int64, role string) (*models.AccountAccess, error) {
+
+	if !models.Roles[role] {
+		return nil, models.BadRequest("Invalid role %s", role)
+	}
+
+	if _, err := r.userDao.GetAccountByID(ctx, accountID); err != nil {
+		return nil, models.NotFound("Account not found")
+	}
+
+	if err := r.roleDao.GrantRole(ctx, accountID, role); err != nil {
+		return nil, err
+	}
+
+	return r.GetAccountAccess(ctx, accountID)
+}
+
+//RevokeRole takes the role away from the account, revoking CENTER_STAFF also
+//removes the center assignments of the account
+func (r *RoleData) RevokeRole(ctx context.Context, accountID int64, role string) (*models.AccountAccess, error) {
+
+	if !models.Roles[role] {
+		return nil, models.BadRequest("Invalid role %s", role)
+	}
+
+	err := r.roleDao.RunInTransaction(ctx, func(roleDao daos.RoleDao) error {
+
+		if err := roleDao.RevokeRole(ctx, accountID, role); err != nil {
+			if err == pg.ErrNoRows {
+				return models.Conflict("Account does not have the %s role", role)
+			}
+			return err
+		}
+
+		if role == models.RoleCenterStaff {
+			return roleDao.UnassignAllCenters(ctx, accountID)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetAccountAccess(ctx, accountID)
+}
+
+func (r *RoleData) GetCenterStaff(ctx context.Context, centerID int64) ([]models.StaffAssignment, error) {
+
+	if _, err := r.vaccineCenterDao.GetVaccineCenterByID(ctx, centerID); err != nil {
+		return nil, models.NotFound("Vaccine center not found")
+	}
+
+	return r.roleDao.GetCenterStaff(ctx, centerID)
+}
+
+//AssignStaff assigns the account to the center, the account needs the CENTER_STAFF role
+func (r *RoleData) AssignStaff(ctx context.Context, centerID, accountID int64) ([]models.StaffAssignment, error) {
+
+	if _, err := r.vaccineCenterDao.GetVaccineCenterByID(ctx, centerID); err != nil {
+		return nil, models.NotFound("Vaccine center not found")
+	}
+
+	isStaff, err := r.roleDao.HasRole(ctx, accountID, models.RoleCenterStaff)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isStaff {
+		return nil, models.Conflict("Account does not have the %s role", models.RoleCenterStaff)
+	}
+
+	if err := r.roleDao.AssignCenter(ctx, accountID, centerID); err != nil {
+		return nil, err
+	}
+
+	return r.roleDao.GetCenterStaff(ctx, centerID)
+}
+
+func (r *RoleData) UnassignStaff(ctx context.Context, centerID, accountID int64) error {
+
+	if err := r.roleDao.UnassignCenter(ctx, accountID, centerID); err != nil {
+		if err == pg.ErrNoRows {
+			return models.NotFound("Account is not assigned to the center")
+		}
+		return err
+	}
+
+	return nil
+}
@@ -1,20 +1,26 @@
 package userRegistration
 
 import (
+	"context"
+	"time"
+
 	"vaccinationDrive/internals/daos"
+	"vaccinationDrive/internals/logger"
+	"vaccinationDrive/internals/pii"
+	"vaccinationDrive/internals/settings"
 	"vaccinationDrive/models"
+	"vaccinationDrive/utils"
 
-	"github.com/FenixAra/go-util/log"
 	"github.com/go-pg/pg"
 )
 
 type UserData struct {
 	dbConn  *pg.DB
-	l       *log.Logger
+	l       *logger.Logger
 	userDao daos.UserDao
 }
 
-func NewUserData(l *log.Logger, dbConn *pg.DB) *UserData {
+func NewUserData(l *logger.Logger, dbConn *pg.DB) *UserData {
 	return &UserData{
 		l:       l,
 		dbConn:  dbConn,
@@ -23,15 +29,107 @@ func NewUserData(l *log.Logger, dbConn *pg.DB) *UserData {
 
 }
 
-func (u *UserData) RegisterUser(user models.User) error {
+//RegisterUser saves the beneficiary as a member of the account of its phone number,
+//the account is created on the first registration. Eligibility is evaluated when booking.
+func (u *UserData) RegisterUser(ctx context.Context, user models.User) (*models.User, error) {
+
+	dob, err := user.DateOfBirth()
+	if err != nil {
+		return nil, err
+	}
+
+	kr, err := pii.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := user.SealIDNumber(kr); err != nil {
+		return nil, err
+	}
 
-	user.BeforeInsert()
-	err := u.userDao.SaveUser(user)
+	//the unique constraint on the hash still catches concurrent registrations
+	exists, err := u.userDao.IDDocumentExists(ctx, user.IDType, user.IDNumberHash)
 	if err != nil {
-		u.l.Errorf("RegisterUser Error -- ", err)
-		return err
+		return nil, err
 	}
+	if exists {
+		return nil, models.ErrIDNumberRegistered
+	}
+
+	user.Age = float64(utils.AgeOn(dob, time.Now().In(utils.IST)))
+	if user.PriorityGroup == "" {
+		user.PriorityGroup = models.PriorityGroupGeneral
+	}
+
+	err = u.userDao.RunInTransaction(ctx, func(userDao daos.UserDao) error {
+
+		account, err := userDao.LockAccount(ctx, user.PhoneNumber)
+		if err != nil {
+			return err
+		}
 
-	return nil
+		count, err := userDao.CountMembers(ctx, account.ID)
+		if err != nil {
+			return err
+		}
+
+		if limit := maxBeneficiariesPerAccount(); count >= limit {
+			return models.Conflict("Only %d beneficiaries can be registered under a phone number", limit)
+		}
+
+		user.ID = 0 // set by an attempt that was rolled back and retried
+		user.AccountID = account.ID
+		user.BeforeInsert()
+		return userDao.SaveUser(ctx, &user)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+
+}
+
+func (u *UserData) GetAccount(ctx context.Context, ID int64) (*models.Account, error) {
+	return u.userDao.GetAccountByID(ctx, ID)
+}
+
+func (u *UserData) GetMembers(ctx context.Context, accountID int64) ([]models.User, error) {
+
+	if _, err := u.userDao.GetAccountByID(ctx, accountID); err != nil {
+		return nil, err
+	}
+
+	return u.userDao.GetMembers(ctx, accountID)
+}
+
+//RemoveMember takes the beneficiary out of the account, its appointment history is kept.
+//Members with appointments yet to be attended have to cancel them first.
+func (u *UserData) RemoveMember(ctx context.Context, accountID, memberID int64) error {
+
+	return u.userDao.RunInTransaction(ctx, func(userDao daos.UserDao) error {
+
+		member, err := userDao.GetUserByID(ctx, memberID)
+		if err != nil || member.AccountID != accountID || !member.IsMember() {
+			return models.NotFound("Member not found in the account")
+		}
+
+		open, err := userDao.CountOpenAppointments(ctx, memberID)
+		if err != nil {
+			return err
+		}
+
+		if open > 0 {
+			return models.Conflict("Cancel the open appointments of the member before removing")
+		}
+
+		now := time.Now().UTC()
+		member.RemovedAt = &now
+		member.UpdatedAt = now
+		return userDao.RemoveMember(ctx, *member)
+	})
+}
 
+func maxBeneficiariesPerAccount() int {
+	return settings.Get().MaxBeneficiariesPerAccount
 }
@@ -0,0 +1,93 @@
+package vaccine
+
+import (
+	"context"
+	"time"
+
+	"vaccinationDrive/internals/daos"
+	"vaccinationDrive/internals/logger"
+	"vaccinationDrive/models"
+
+	"github.com/go-pg/pg"
+)
+
+type VaccineData struct {
+	dbConn     *pg.DB
+	l          *logger.Logger
+	vaccineDao daos.VaccineDao
+}
+
+func NewVaccineData(l *logger.Logger, dbConn *pg.DB) *VaccineData {
+	return &VaccineData{
+		l:          l,
+		dbConn:     dbConn,
+		vaccineDao: daos.NewVaccineData(l, dbConn),
+	}
+}
+
+func (v *VaccineData) CreateVaccine(ctx context.Context, vaccine models.Vaccine) (*models.Vaccine, error) {
+
+	if err := v.checkName(ctx, vaccine); err != nil {
+		return nil, err
+	}
+
+	vaccine.IsActive = true
+	vaccine.BeforeInsert()
+
+	err := v.vaccineDao.SaveVaccine(ctx, &vaccine)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vaccine, nil
+}
+
+func (v *VaccineData) GetVaccines(ctx context.Context, activeOnly bool) ([]models.Vaccine, error) {
+	return v.vaccineDao.GetVaccines(ctx, activeOnly)
+}
+
+func (v *VaccineData) GetVaccine(ctx context.Context, ID int64) (*models.Vaccine, error) {
+	return v.vaccineDao.GetVaccineByID(ctx, ID)
+}
+
+func (v *VaccineData) UpdateVaccine(ctx context.Context, vaccine models.Vaccine) (*models.Vaccine, error) {
+
+	if _, err := v.vaccineDao.GetVaccineByID(ctx, vaccine.ID); err != nil {
+		return nil, err
+	}
+
+	if err := v.checkName(ctx, vaccine); err != nil {
+		return nil, err
+	}
+
+	vaccine.UpdatedAt = time.Now().UTC()
+	err := v.vaccineDao.UpdateVaccine(ctx, &vaccine)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vaccine, nil
+}
+
+func (v *VaccineData) DeactivateVaccine(ctx context.Context, ID int64) error {
+
+	err := v.vaccineDao.DeactivateVaccine(ctx, ID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+//checkName rejects the name of another vaccine, a concurrent insert of the name is
+//caught by the vaccines_name_key index instead
+func (v *VaccineData) checkName(ctx context.Context, vaccine models.Vaccine) error {
+	exists, err := v.vaccineDao.VaccineNameExists(ctx, vaccine.Name, vaccine.ID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return models.ErrVaccineExists
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package vaccineCenter
+
+import (
+	"context"
+	"time"
+
+	"vaccinationDrive/internals/daos"
+	"vaccinationDrive/internals/logger"
+	"vaccinationDrive/models"
+
+	"github.com/go-pg/pg"
+)
+
+type VaccineCenterData struct {
+	dbConn           *pg.DB
+	l                *logger.Logger
+	vaccineCenterDao daos.VaccineCenterDao
+}
+
+func NewVaccineCenterData(l *logger.Logger, dbConn *pg.DB) *VaccineCenterData {
+	return &VaccineCenterData{
+		l:                l,
+		dbConn:           dbConn,
+		vaccineCenterDao: daos.NewVaccineCenterData(l, dbConn),
+	}
+}
+
+func (v *VaccineCenterData) CreateVaccineCenter(ctx context.Context, center models.VaccineCenter) (*models.VaccineCenter, error) {
+
+	if err := v.checkName(ctx, center); err != nil {
+		return nil, err
+	}
+
+	center.IsActive = true
+	center.BeforeInsert()
+
+	err := v.vaccineCenterDao.SaveVaccineCenter(ctx, &center)
+	if err != nil {
+		return nil, err
+	}
+
+	return &center, nil
+}
+
+func (v *VaccineCenterData) GetVaccineCenters(ctx context.Context, district string, activeOnly bool) ([]models.VaccineCenter, error) {
+	return v.vaccineCenterDao.GetVaccineCenters(ctx, district, activeOnly)
+}
+
+func (v *VaccineCenterData) GetVaccineCenter(ctx context.Context, ID int64) (*models.VaccineCenter, error) {
+	return v.vaccineCenterDao.GetVaccineCenterByID(ctx, ID)
+}
+
+func (v *VaccineCenterData) UpdateVaccineCenter(ctx context.Context, center models.VaccineCenter) (*models.VaccineCenter, error) {
+
+	if _, err := v.vaccineCenterDao.GetVaccineCenterByID(ctx, center.ID); err != nil {
+		return nil, err
+	}
+
+	if err := v.checkName(ctx, center); err != nil {
+		return nil, err
+	}
+
+	center.UpdatedAt = time.Now().UTC()
+	err := v.vaccineCenterDao.UpdateVaccineCenter(ctx, &center)
+	if err != nil {
+		return nil, err
+	}
+
+	return &center, nil
+}
+
+func (v *VaccineCenterData) DeactivateVaccineCenter(ctx context.Context, ID int64) error {
+
+	err := v.vaccineCenterDao.DeactivateVaccineCenter(ctx, ID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+//checkName rejects the name of another center of the district, a concurrent insert of
+//the name is caught by the vaccine_centers_name_district_key index instead
+func (v *VaccineCenterData) checkName(ctx context.Context, center models.VaccineCenter) error {
+	exists, err := v.vaccineCenterDao.VaccineCenterNameExists(ctx, center.Name, center.District, center.ID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return models.ErrVaccineCenterExists
+	}
+	return nil
+}
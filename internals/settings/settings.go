@@ -0,0 +1,209 @@
+// Package settings holds the config values that can change while the server runs
+package settings
+
+import (
+	"log"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"syscall"
+	"time"
+
+	"vaccinationDrive/conf"
+)
+
+//Settings are the reloadable values of the config, fields tagged reload:"true".
+//A Settings is never modified once built, a reload swaps in a new one.
+type Settings struct {
+	LogLevel                   string         `json:"logLevel"`
+	BookingWindowDays          int            `json:"bookingWindowDays"`
+	CancellationCutoffHours    int            `json:"cancellationCutoffHours"`
+	MaxBeneficiariesPerAccount int            `json:"maxBeneficiariesPerAccount"`
+	DefaultDailyQuota          int            `json:"defaultDailyQuota"`
+	DefaultSlotQuotas          map[string]int `json:"defaultSlotQuotas"`
+	TxMaxRetries               int            `json:"txMaxRetries"`
+	TxRetryBaseDelayMs         int            `json:"txRetryBaseDelayMs"`
+	TxRetryMaxDelayMs          int            `json:"txRetryMaxDelayMs"`
+	QueryTimeoutMs             int            `json:"queryTimeoutMs"`
+	RouteQueryTimeouts         map[string]int `json:"routeQueryTimeouts"`
+}
+
+//QueryTimeout returns the timeout of the db work of a route, Eg. "GET /appointments/:id"
+func (s *Settings) QueryTimeout(route string) time.Duration {
+	if ms, ok := s.RouteQueryTimeouts[route]; ok {
+		return time.Duration(ms) * time.Millisecond
+	}
+	return time.Duration(s.QueryTimeoutMs) * time.Millisecond
+}
+
+//Status is the current settings along with the outcome of the reloads
+type Status struct {
+	Settings       *Settings  `json:"settings"`
+	File           string     `json:"file"`
+	LoadedAt       time.Time  `json:"loadedAt"`
+	ReloadedAt     *time.Time `json:"reloadedAt,omitempty"`
+	LastError      string     `json:"lastError,omitempty"`
+	LastErrorAt    *time.Time `json:"lastErrorAt,omitempty"`
+	IgnoredChanges []string   `json:"ignoredChanges,omitempty"` // fields changed in the file that need a restart
+}
+
+//FromConfig builds the settings of a config
+func FromConfig(cfg conf.Config) *Settings {
+	slotQuotas := map[string]int{}
+	for slot, quota := range cfg.DEFAULT_SLOT_QUOTAS {
+		slotQuotas[slot] = quota
+	}
+
+	routeTimeouts := map[string]int{}
+	for route, timeout := range cfg.ROUTE_QUERY_TIMEOUTS {
+		routeTimeouts[route] = timeout
+	}
+
+	return &Settings{
+		LogLevel:                   cfg.LOG_LEVEL,
+		BookingWindowDays:          cfg.BOOKING_WINDOW_DAYS,
+		CancellationCutoffHours:    cfg.CANCELLATION_CUTOFF_HOURS,
+		MaxBeneficiariesPerAccount: cfg.MAX_BENEFICIARIES_PER_ACCOUNT,
+		DefaultDailyQuota:          cfg.DEFAULT_DAILY_QUOTA,
+		DefaultSlotQuotas:          slotQuotas,
+		TxMaxRetries:               cfg.TX_MAX_RETRIES,
+		TxRetryBaseDelayMs:         cfg.TX_RETRY_BASE_DELAY_MS,
+		TxRetryMaxDelayMs:          cfg.TX_RETRY_MAX_DELAY_MS,
+		QueryTimeoutMs:             cfg.QUERY_TIMEOUT_MS,
+		RouteQueryTimeouts:         routeTimeouts,
+	}
+}
+
+var (
+	status  Status
+	modTime time.Time
+	mu      sync.RWMutex
+)
+
+//Init loads the settings of conf.Cfg, file is reloaded by Reload and Watch
+func Init(file string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	status = Status{Settings: FromConfig(conf.Cfg), File: file, LoadedAt: time.Now().UTC()}
+	if fi, err := os.Stat(file); err == nil {
+		modTime = fi.ModTime()
+	}
+}
+
+//Get returns the current settings, before Init they are built from conf.Cfg
+func Get() *Settings {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if status.Settings == nil {
+		return FromConfig(conf.Cfg)
+	}
+	return status.Settings
+}
+
+//GetStatus returns the current settings and when they were reloaded
+func GetStatus() Status {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	st := status
+	if st.Settings == nil {
+		st.Settings = FromConfig(conf.Cfg)
+	}
+	return st
+}
+
+//Reload reads the config file again and swaps in its settings. An invalid file
+//leaves the current settings in place, changes to the other fields are ignored
+//until the server is restarted.
+func Reload() error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	now := time.Now().UTC()
+	if fi, err := os.Stat(status.File); err == nil {
+		modTime = fi.ModTime()
+	}
+
+	cfg, err := conf.Load(status.File)
+	if err != nil {
+		log.Printf("Settings not reloaded, err:%s", err.Error())
+		status.LastError = err.Error()
+		status.LastErrorAt = &now
+		return err
+	}
+
+	ignored := ignoredChanges(conf.Cfg, *cfg)
+	for _, field := range ignored {
+		log.Printf("Settings reload ignores %s, restart to apply it", field)
+	}
+
+	status.Settings = FromConfig(*cfg)
+	status.ReloadedAt = &now
+	status.IgnoredChanges = ignored
+	status.LastError = ""
+	status.LastErrorAt = nil
+	log.Printf("Settings reloaded from %s", status.File)
+	return nil
+}
+
+//Watch reloads the settings on SIGHUP and when the modification time of the file
+//changes, checking every interval. The returned func stops watching.
+func Watch(interval time.Duration) func() {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-hup:
+				Reload()
+			case <-ticker.C:
+				if changed() {
+					Reload()
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(hup)
+		ticker.Stop()
+		close(done)
+	}
+}
+
+func changed() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	fi, err := os.Stat(status.File)
+	if err != nil {
+		return false
+	}
+	return !fi.ModTime().Equal(modTime)
+}
+
+//ignoredChanges lists the fields, not tagged reload:"true", that differ between the configs
+func ignoredChanges(running, loaded conf.Config) []string {
+	ignored := []string{}
+
+	rv, lv := reflect.ValueOf(running), reflect.ValueOf(loaded)
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("reload") == "true" {
+			continue
+		}
+		if !reflect.DeepEqual(rv.Field(i).Interface(), lv.Field(i).Interface()) {
+			ignored = append(ignored, t.Field(i).Name)
+		}
+	}
+	return ignored
+}
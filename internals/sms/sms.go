@@ -0,0 +1,104 @@
+// Package sms delivers text messages to beneficiaries
+package sms
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	ProviderStderr = "stderr"
+	ProviderFile   = "file"
+)
+
+//ErrNoProvider is returned by the sender used before Init
+var ErrNoProvider = errors.New("sms: no provider configured")
+
+//Sender delivers a text message to a phone number
+type Sender interface {
+	Send(phoneNumber, message string) error
+}
+
+//WriterSender is the development sender, it writes every message as a line to the writer.
+//The lines hold the phone number and the OTP, so it never writes to stdout where the
+//json logs go.
+type WriterSender struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+//NewWriterSender returns a sender writing to w
+func NewWriterSender(w io.Writer) *WriterSender {
+	return &WriterSender{w: w}
+}
+
+//Send writes the message to the underlying writer
+func (s *WriterSender) Send(phoneNumber, message string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := fmt.Fprintf(s.w, "%s SMS to %s: %s\n", time.Now().UTC().Format(time.RFC3339), phoneNumber, message)
+	return err
+}
+
+var (
+	sender Sender
+	mu     sync.RWMutex
+)
+
+//Init sets up the sender of the configured provider, stderr is only allowed in dev mode
+func Init(provider, filePath string, devMode bool) error {
+	var s Sender
+
+	switch provider {
+	case "":
+		return errors.New("sms: provider is required")
+	case ProviderStderr:
+		if !devMode {
+			return fmt.Errorf("sms: the %s provider is only allowed in dev mode", ProviderStderr)
+		}
+		s = NewWriterSender(os.Stderr)
+	case ProviderFile:
+		if filePath == "" {
+			return fmt.Errorf("sms: file path is required for the %s provider", ProviderFile)
+		}
+		f, err := os.OpenFile(filePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+		if err != nil {
+			return fmt.Errorf("sms: unable to open %s: %v", filePath, err)
+		}
+		s = NewWriterSender(f)
+	default:
+		return fmt.Errorf("sms: unknown provider %s", provider)
+	}
+
+	SetSender(s)
+	return nil
+}
+
+//SetSender replaces the sender, tests use it to capture messages
+func SetSender(s Sender) {
+	mu.Lock()
+	defer mu.Unlock()
+	sender = s
+}
+
+//Get returns the configured sender, one failing with ErrNoProvider when Init was not called
+func Get() Sender {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if sender == nil {
+		return noSender{}
+	}
+	return sender
+}
+
+type noSender struct{}
+
+func (noSender) Send(phoneNumber, message string) error {
+	return ErrNoProvider
+}
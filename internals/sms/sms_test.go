@@ -0,0 +1,76 @@
+package sms
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+//captureSender keeps the messages instead of sending them
+type captureSender struct {
+	messages map[string]string
+}
+
+func (c *captureSender) Send(phoneNumber, message string) error {
+	c.messages[phoneNumber] = message
+	return nil
+}
+
+func TestSetSenderCapturesMessages(t *testing.T) {
+	defer SetSender(nil)
+
+	if err := Get().Send("9876543210", "hello"); !errors.Is(err, ErrNoProvider) {
+		t.Fatalf("expected ErrNoProvider before Init, got %v", err)
+	}
+
+	c := &captureSender{messages: map[string]string{}}
+	SetSender(c)
+
+	if err := Get().Send("9876543210", "123456 is your OTP"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.messages["9876543210"] != "123456 is your OTP" {
+		t.Fatalf("message not captured, got %v", c.messages)
+	}
+}
+
+func TestWriterSenderWritesOneLine(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := NewWriterSender(buf).Send("9876543210", "123456 is your OTP"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	line := buf.String()
+	if !strings.HasSuffix(line, "SMS to 9876543210: 123456 is your OTP\n") || strings.Count(line, "\n") != 1 {
+		t.Fatalf("unexpected line %q", line)
+	}
+}
+
+func TestInitProviders(t *testing.T) {
+	defer SetSender(nil)
+
+	tests := []struct {
+		name     string
+		provider string
+		filePath string
+		devMode  bool
+		wantErr  bool
+	}{
+		{"empty provider", "", "", true, true},
+		{"unknown provider", "carrier-pigeon", "", true, true},
+		{"stderr outside dev mode", ProviderStderr, "", false, true},
+		{"stderr in dev mode", ProviderStderr, "", true, false},
+		{"file without a path", ProviderFile, "", false, true},
+		{"file", ProviderFile, t.TempDir() + "/sms.log", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Init(tt.provider, tt.filePath, tt.devMode)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Init(%q) error = %v, wantErr %v", tt.provider, err, tt.wantErr)
+			}
+		})
+	}
+}
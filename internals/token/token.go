@@ -0,0 +1,364 @@
+// Package token signs and verifies the RS256 JWT access tokens
+package token
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"vaccinationDrive/conf"
+)
+
+const algorithm = "RS256"
+
+var (
+	ErrInvalidToken = errors.New("token: invalid token")
+	ErrExpiredToken = errors.New("token: token has expired")
+	ErrNoKeys       = errors.New("token: keys are not loaded")
+)
+
+//Claims of the access token
+type Claims struct {
+	Issuer    string `json:"iss"`
+	Subject   string `json:"sub"`
+	SessionID int64  `json:"sid"`
+	ID        string `json:"jti"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+type header struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ"`
+	KID       string `json:"kid"`
+}
+
+//JWK is the public part of a key as published in the JWKS
+type JWK struct {
+	KTY       string `json:"kty"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	KID       string `json:"kid"`
+	N         string `json:"n"`
+	E         string `json:"e"`
+}
+
+//JWKS is the document served to the gateway for verifying tokens
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+type key struct {
+	kid     string
+	private *rsa.PrivateKey
+	public  *rsa.PublicKey
+}
+
+//KeySet holds every active key, new tokens are signed with the signing key
+type KeySet struct {
+	issuer  string
+	signing *key
+	keys    map[string]*key
+	order   []string
+}
+
+//NewKeySet loads the keys from their PEM files, signingKID picks the key used for
+//signing and defaults to the first key with a private key.
+//Keys with only a public key verify tokens signed before a rotation but never sign new ones.
+func NewKeySet(issuer, signingKID string, configs []conf.JWTKey) (*KeySet, error) {
+	ks := &KeySet{issuer: issuer, keys: map[string]*key{}}
+
+	for _, c := range configs {
+		if c.KID == "" {
+			return nil, errors.New("token: every key needs a kid")
+		}
+		if _, ok := ks.keys[c.KID]; ok {
+			return nil, fmt.Errorf("token: duplicate kid %s", c.KID)
+		}
+
+		k, err := loadKey(c)
+		if err != nil {
+			return nil, err
+		}
+
+		ks.keys[c.KID] = k
+		ks.order = append(ks.order, c.KID)
+
+		if ks.signing == nil && k.private != nil && (signingKID == "" || signingKID == c.KID) {
+			ks.signing = k
+		}
+	}
+
+	if ks.signing == nil {
+		return nil, fmt.Errorf("token: no private key found for signing kid %q", signingKID)
+	}
+
+	return ks, nil
+}
+
+//NewEphemeralKeySet generates a single in memory key, tokens do not survive a restart
+func NewEphemeralKeySet(issuer string) (*KeySet, error) {
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	k := &key{kid: "ephemeral", private: private, public: &private.PublicKey}
+	return &KeySet{
+		issuer:  issuer,
+		signing: k,
+		keys:    map[string]*key{k.kid: k},
+		order:   []string{k.kid},
+	}, nil
+}
+
+func loadKey(c conf.JWTKey) (*key, error) {
+	k := &key{kid: c.KID}
+
+	switch {
+	case c.PrivateKeyFile != "":
+		block, err := readPEM(c.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		private, err := parsePrivateKey(block)
+		if err != nil {
+			return nil, fmt.Errorf("token: key %s: %v", c.KID, err)
+		}
+		k.private = private
+		k.public = &private.PublicKey
+
+	case c.PublicKeyFile != "":
+		block, err := readPEM(c.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		public, err := parsePublicKey(block)
+		if err != nil {
+			return nil, fmt.Errorf("token: key %s: %v", c.KID, err)
+		}
+		k.public = public
+
+	default:
+		return nil, fmt.Errorf("token: key %s needs a private or a public key file", c.KID)
+	}
+
+	return k, nil
+}
+
+func readPEM(file string) (*pem.Block, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("token: unable to read %s: %v", file, err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("token: %s is not a PEM file", file)
+	}
+
+	return block, nil
+}
+
+func parsePrivateKey(block *pem.Block) (*rsa.PrivateKey, error) {
+	if block.Type == "RSA PRIVATE KEY" {
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+
+	k, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	private, ok := k.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("not an RSA private key")
+	}
+	return private, nil
+}
+
+func parsePublicKey(block *pem.Block) (*rsa.PublicKey, error) {
+	if block.Type == "RSA PUBLIC KEY" {
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	}
+
+	k, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	public, ok := k.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("not an RSA public key")
+	}
+	return public, nil
+}
+
+//Sign issues the token for the claims with the signing key, the issuer is filled in
+func (ks *KeySet) Sign(claims Claims) (string, error) {
+	claims.Issuer = ks.issuer
+
+	h, err := encodeSegment(header{Algorithm: algorithm, Type: "JWT", KID: ks.signing.kid})
+	if err != nil {
+		return "", err
+	}
+
+	c, err := encodeSegment(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signed := h + "." + c
+	digest := sha256.Sum256([]byte(signed))
+
+	sig, err := rsa.SignPKCS1v15(rand.Reader, ks.signing.private, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+//Verify checks the signature against the key of the kid header and returns the
+//claims of a token which is not expired
+func (ks *KeySet) Verify(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+
+	h := header{}
+	if err := decodeSegment(parts[0], &h); err != nil || h.Algorithm != algorithm {
+		return nil, ErrInvalidToken
+	}
+
+	k, ok := ks.keys[h.KID]
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(k.public, crypto.SHA256, digest[:], sig); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	claims := Claims{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if claims.Issuer != ks.issuer {
+		return nil, ErrInvalidToken
+	}
+
+	if time.Now().Unix() >= claims.ExpiresAt {
+		return nil, ErrExpiredToken
+	}
+
+	return &claims, nil
+}
+
+//JWKS returns the public keys of every active key
+func (ks *KeySet) JWKS() JWKS {
+	set := JWKS{Keys: []JWK{}}
+
+	for _, kid := range ks.order {
+		k := ks.keys[kid]
+		set.Keys = append(set.Keys, JWK{
+			KTY:       "RSA",
+			Use:       "sig",
+			Algorithm: algorithm,
+			KID:       kid,
+			N:         base64.RawURLEncoding.EncodeToString(k.public.N.Bytes()),
+			E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.public.E)).Bytes()),
+		})
+	}
+
+	return set
+}
+
+func encodeSegment(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func decodeSegment(seg string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+var (
+	keySet *KeySet
+	mu     sync.RWMutex
+)
+
+//Init loads the keys of the config. Without keys an ephemeral key is generated in dev
+//mode, the tokens it signs don't survive a restart and aren't accepted by other replicas.
+func Init() error {
+	var (
+		ks  *KeySet
+		err error
+	)
+
+	if len(conf.Cfg.JWT_KEYS) == 0 {
+		if !conf.Cfg.DEV_MODE {
+			return errors.New("token: jwt_keys are required outside dev_mode")
+		}
+		log.Println("WARNING: no jwt keys configured, tokens are signed with an ephemeral key")
+		ks, err = NewEphemeralKeySet(issuer())
+	} else {
+		ks, err = NewKeySet(issuer(), conf.Cfg.JWT_SIGNING_KID, conf.Cfg.JWT_KEYS)
+	}
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	keySet = ks
+	return nil
+}
+
+//Get returns the key set loaded by Init
+func Get() (*KeySet, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if keySet == nil {
+		return nil, ErrNoKeys
+	}
+	return keySet, nil
+}
+
+func issuer() string {
+	if conf.Cfg.JWT_ISSUER != "" {
+		return conf.Cfg.JWT_ISSUER
+	}
+	return conf.Cfg.APP_NAME
+}
@@ -0,0 +1,175 @@
+package token
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"vaccinationDrive/conf"
+)
+
+//writeKey generates an RSA key and writes its private and public PEM files to dir
+func writeKey(t *testing.T, dir, kid string) (privateFile, publicFile string) {
+	t.Helper()
+
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unable to generate key: %v", err)
+	}
+
+	public, err := x509.MarshalPKIXPublicKey(&private.PublicKey)
+	if err != nil {
+		t.Fatalf("unable to marshal public key: %v", err)
+	}
+
+	privateFile = filepath.Join(dir, kid+".pem")
+	publicFile = filepath.Join(dir, kid+".pub.pem")
+
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(private)})
+	if err := ioutil.WriteFile(privateFile, privatePEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: public})
+	if err := ioutil.WriteFile(publicFile, publicPEM, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return privateFile, publicFile
+}
+
+func newKeySet(t *testing.T, signingKID string, keys ...conf.JWTKey) *KeySet {
+	t.Helper()
+
+	ks, err := NewKeySet("test", signingKID, keys)
+	if err != nil {
+		t.Fatalf("NewKeySet: %v", err)
+	}
+	return ks
+}
+
+func sign(t *testing.T, ks *KeySet, ttl time.Duration) string {
+	t.Helper()
+
+	now := time.Now()
+	tok, err := ks.Sign(Claims{Subject: "42", SessionID: 7, ID: "jti", IssuedAt: now.Unix(), ExpiresAt: now.Add(ttl).Unix()})
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	return tok
+}
+
+func TestSignVerify(t *testing.T) {
+	dir := t.TempDir()
+	oldPrivate, oldPublic := writeKey(t, dir, "old")
+	newPrivate, _ := writeKey(t, dir, "new")
+	otherPrivate, _ := writeKey(t, dir, "other")
+
+	oldKeys := newKeySet(t, "", conf.JWTKey{KID: "old", PrivateKeyFile: oldPrivate})
+	//the old key is kept with its public key only after the rotation
+	rotated := newKeySet(t, "new", conf.JWTKey{KID: "old", PublicKeyFile: oldPublic}, conf.JWTKey{KID: "new", PrivateKeyFile: newPrivate})
+	//the old key is removed once its tokens have expired
+	rotatedOut := newKeySet(t, "", conf.JWTKey{KID: "new", PrivateKeyFile: newPrivate})
+	//same kid as the old key but a different private key
+	impostor := newKeySet(t, "", conf.JWTKey{KID: "old", PrivateKeyFile: otherPrivate})
+	otherIssuer, err := NewKeySet("other", "", []conf.JWTKey{{KID: "old", PrivateKeyFile: oldPrivate}})
+	if err != nil {
+		t.Fatalf("NewKeySet: %v", err)
+	}
+
+	valid := sign(t, oldKeys, time.Minute)
+	parts := strings.Split(valid, ".")
+
+	tamperedSig := []byte(parts[2])
+	if tamperedSig[0] == 'A' {
+		tamperedSig[0] = 'B'
+	} else {
+		tamperedSig[0] = 'A'
+	}
+
+	tamperedClaims := base64.RawURLEncoding.EncodeToString([]byte(`{"iss":"test","sub":"1","sid":7,"jti":"jti","exp":9999999999}`))
+
+	tests := []struct {
+		name  string
+		ks    *KeySet
+		token string
+		err   error
+	}{
+		{"valid", oldKeys, valid, nil},
+		{"expired", oldKeys, sign(t, oldKeys, -time.Second), ErrExpiredToken},
+		{"tampered signature", oldKeys, parts[0] + "." + parts[1] + "." + string(tamperedSig), ErrInvalidToken},
+		{"tampered claims", oldKeys, parts[0] + "." + tamperedClaims + "." + parts[2], ErrInvalidToken},
+		{"unsigned", oldKeys, parts[0] + "." + parts[1] + ".", ErrInvalidToken},
+		{"malformed", oldKeys, "not-a-token", ErrInvalidToken},
+		{"unknown kid", oldKeys, sign(t, rotatedOut, time.Minute), ErrInvalidToken},
+		{"wrong key for kid", oldKeys, sign(t, impostor, time.Minute), ErrInvalidToken},
+		{"other issuer", oldKeys, sign(t, otherIssuer, time.Minute), ErrInvalidToken},
+		{"signed before rotation", rotated, valid, nil},
+		{"signed after rotation", rotated, sign(t, rotated, time.Minute), nil},
+		{"rotated out key", rotatedOut, valid, ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := tt.ks.Verify(tt.token)
+			if err != tt.err {
+				t.Fatalf("Verify error = %v, want %v", err, tt.err)
+			}
+			if err == nil && (claims.Subject != "42" || claims.SessionID != 7 || claims.Issuer != "test") {
+				t.Errorf("Verify claims = %+v", claims)
+			}
+		})
+	}
+}
+
+func TestSignUsesSigningKey(t *testing.T) {
+	dir := t.TempDir()
+	oldPrivate, oldPublic := writeKey(t, dir, "old")
+	newPrivate, _ := writeKey(t, dir, "new")
+
+	ks := newKeySet(t, "new", conf.JWTKey{KID: "old", PrivateKeyFile: oldPrivate}, conf.JWTKey{KID: "new", PrivateKeyFile: newPrivate})
+
+	h := header{}
+	if err := decodeSegment(strings.Split(sign(t, ks, time.Minute), ".")[0], &h); err != nil {
+		t.Fatal(err)
+	}
+	if h.KID != "new" || h.Algorithm != algorithm {
+		t.Errorf("header = %+v, want kid new and %s", h, algorithm)
+	}
+
+	if _, err := NewKeySet("test", "old", []conf.JWTKey{{KID: "old", PublicKeyFile: oldPublic}}); err == nil {
+		t.Error("NewKeySet signing with a public key only, want an error")
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	dir := t.TempDir()
+	oldPrivate, oldPublic := writeKey(t, dir, "old")
+	newPrivate, _ := writeKey(t, dir, "new")
+
+	ks := newKeySet(t, "new", conf.JWTKey{KID: "old", PublicKeyFile: oldPublic}, conf.JWTKey{KID: "new", PrivateKeyFile: newPrivate})
+	oldKeys := newKeySet(t, "", conf.JWTKey{KID: "old", PrivateKeyFile: oldPrivate})
+
+	set := ks.JWKS()
+	if len(set.Keys) != 2 || set.Keys[0].KID != "old" || set.Keys[1].KID != "new" {
+		t.Fatalf("JWKS keys = %+v, want old and new", set.Keys)
+	}
+
+	//the published key of old is the one its private key signs with
+	if got, want := set.Keys[0], oldKeys.JWKS().Keys[0]; got != want {
+		t.Errorf("JWKS old key = %+v, want %+v", got, want)
+	}
+
+	for _, k := range set.Keys {
+		if k.KTY != "RSA" || k.Use != "sig" || k.Algorithm != algorithm || k.E != "AQAB" {
+			t.Errorf("JWKS key = %+v", k)
+		}
+	}
+}
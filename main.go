@@ -1,99 +1,150 @@
 package main
 
 import (
-	"context"
 	"flag"
 	"fmt"
 	"log"
-	"net/http"
 	"os"
-	"os/signal"
 	"runtime"
+	"sort"
 	"strconv"
 	"time"
 	"vaccinationDrive/conf"
 	"vaccinationDrive/dbcon"
-	"vaccinationDrive/dbscripts"
-	"vaccinationDrive/routes"
-
-	"github.com/rs/cors"
+	"vaccinationDrive/internals/logger"
+	"vaccinationDrive/internals/pii"
+	"vaccinationDrive/internals/settings"
+	"vaccinationDrive/internals/sms"
+	"vaccinationDrive/internals/token"
 )
 
+//command is a subcommand of the binary, setup registers its flags and returns the function
+//that runs it with the remaining arguments once the config is parsed and the db is connected.
+//A configOnly command runs right after the config is parsed.
+type command struct {
+	usage      string
+	setup      func(fs *flag.FlagSet) func(args []string) error
+	configOnly bool
+}
+
+var commands = map[string]command{
+	"serve": {
+		usage: "serve                                   start the http server (default)",
+		setup: func(fs *flag.FlagSet) func(args []string) error { return runServe },
+	},
+	"migrate": {
+		usage: "migrate up | down [steps] | status | force <version>",
+		setup: func(fs *flag.FlagSet) func(args []string) error { return runMigrate },
+	},
+	"seed": {
+		usage: "seed                                    add sample centers, vaccines and users",
+		setup: func(fs *flag.FlagSet) func(args []string) error { return runSeed },
+	},
+	"create-admin": {
+		usage: "create-admin -phone <phone number>      grant ADMIN to the account of the phone number",
+		setup: createAdminCommand,
+	},
+	"config": {
+		usage:      "config check                            print the effective config, secrets redacted",
+		setup:      func(fs *flag.FlagSet) func(args []string) error { return runConfig },
+		configOnly: true,
+	},
+	"rotate-pii": {
+		usage: "rotate-pii [-batch <n>]                 re-encrypt the ID numbers with the current pii key",
+		setup: rotatePIICommand,
+	},
+	"export": {
+		usage: "export appointments -date <DD/MM/YYYY> -center <id> [-out <file>]",
+		setup: exportCommand,
+	},
+}
+
 func main() {
 	log.Println(time.Now().UTC())
 
-	var configFile = flag.String("conf", "", "configuration file(mandatory)")
-
+	var configFile string
+	flag.StringVar(&configFile, "conf", "", "configuration file(mandatory)")
+	flag.Usage = usage
 	flag.Parse()
-	if flag.NFlag() != 1 {
-		flag.PrintDefaults()
+
+	name, args := "serve", flag.Args()
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
+
+	cmd, ok := commands[name]
+	if !ok {
+		usage()
+		os.Exit(1)
+	}
+
+	//-conf is also accepted after the command
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.StringVar(&configFile, "conf", configFile, "configuration file(mandatory)")
+	run := cmd.setup(fs)
+	fs.Parse(args)
+
+	if configFile == "" {
+		usage()
 		os.Exit(1)
 	}
 
 	// Parsing configuration
-	if err := conf.Parse(*configFile); err != nil {
+	if err := conf.Parse(configFile); err != nil {
 		log.Fatalln("ERROR: ", err)
 	}
 
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 
+	settings.Init(configFile)
+
+	if cmd.configOnly {
+		if err := run(fs.Args()); err != nil {
+			log.Fatalln("ERROR: ", err)
+		}
+		return
+	}
+
 	cpu, _ := strconv.Atoi(os.Getenv("GOMAXPROCS"))
 	runtime.GOMAXPROCS(cpu)
 	log.Println("INFO: Number of cpu configured - ", cpu)
 
-	dbcon.Connect()
-	defer dbcon.Close()
-
-	dbscripts.InitDB()
-
-	router := routes.RouterConfig()
-	//r := chi.NewRouter()
-
-	c := cors.New(cors.Options{
-		AllowedOrigins: []string{"*"},
-		AllowedMethods: []string{"GET", "POST", "DELETE", "PUT", "OPTIONS"},
-		AllowedHeaders: []string{"Origin", "X-Requested-With", "Content-Type", "Accept",
-			"Authorization", "Access-Control-Allow-Headers", "Access-Control-Allow-Origin"},
-	})
-
-	server := http.Server{
-		Addr:         fmt.Sprintf(":%d", conf.Cfg.PORT),
-		ReadTimeout:  90 * time.Second,
-		WriteTimeout: 90 * time.Second,
-		Handler:      c.Handler(router),
+	if err := sms.Init(conf.Cfg.SMS_PROVIDER, conf.Cfg.SMS_FILE_PATH, conf.Cfg.DEV_MODE); err != nil {
+		log.Fatalln("ERROR: ", err)
 	}
 
-	done := make(chan bool)
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, os.Interrupt)
-
-	//Graceful shut down
-	go func() {
-		<-quit
-		log.Println("Server is shutting down...")
+	if err := token.Init(); err != nil {
+		log.Fatalln("ERROR: ", err)
+	}
 
-		//Close resources before shut down
-		dbcon.Close()
+	if err := pii.Init(); err != nil {
+		log.Fatalln("ERROR: ", err)
+	}
 
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
+	dbcon.Connect()
 
-		//Shutdown server
-		server.SetKeepAlivesEnabled(false)
-		if err := server.Shutdown(ctx); err != nil {
-			log.Fatalf("Unable to gracefully shutdown the server: %v\n", err)
-		}
+	err := run(fs.Args())
+	dbcon.Close()
+	if err != nil {
+		log.Fatalln("ERROR: ", err)
+	}
+}
 
-		//Close channels
-		close(quit)
-		close(done)
-	}()
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: %s -conf <file> [command]\n\ncommands:\n", os.Args[0])
 
-	log.Printf("Listening on: %d", conf.Cfg.PORT)
+	names := []string{}
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
 
-	if err := server.ListenAndServe(); err != http.ErrServerClosed {
-		log.Fatalf("Error in listening server: %s", err.Error())
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %s\n", commands[name].usage)
 	}
-	<-done
-	log.Fatal("Server stopped")
+}
+
+//newLogger is the logger handed to the services by the commands
+func newLogger() *logger.Logger {
+	return logger.New(settings.Get().LogLevel)
 }
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"vaccinationDrive/dbcon"
+	"vaccinationDrive/dbscripts"
+)
+
+const migrateUsage = "usage: migrate up | down [steps] | status | force <version>"
+
+//runMigrate runs the migrate command, Eg. -conf conf/dev.json migrate up
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	db := dbcon.Get()
+
+	switch args[0] {
+	case "up":
+		return dbscripts.MigrateUp(db)
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid steps %q", args[1])
+			}
+			steps = n
+		}
+		return dbscripts.MigrateDown(db, steps)
+
+	case "force":
+		if len(args) < 2 {
+			return errors.New(migrateUsage)
+		}
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		return dbscripts.ForceMigration(db, version)
+
+	case "status":
+		states, err := dbscripts.GetMigrationStates(db)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tSTATE\tAPPLIED AT")
+		for _, state := range states {
+			status, appliedAt := "pending", ""
+			if state.Dirty {
+				status = "dirty"
+			} else if state.Applied {
+				status = "applied"
+			}
+			if state.AppliedAt != nil && !state.Dirty {
+				appliedAt = state.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", state.Version, state.Name, status, appliedAt)
+		}
+		return w.Flush()
+	}
+
+	return errors.New(migrateUsage)
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+//Account is the household registered under a phone number, it owns the beneficiaries
+type Account struct {
+	ID          int64     `json:"id"`
+	PhoneNumber string    `json:"phoneNumber" sql:",notnull,unique"`
+	Members     []*User   `json:"members,omitempty"`
+	CreatedAt   time.Time `json:"createdAt" sql:",default:now()"`
+	UpdatedAt   time.Time `json:"updatedAt" sql:",default:now()"`
+}
+
+// BeforeInsert func
+func (ac *Account) BeforeInsert() {
+	ac.CreatedAt = time.Now().UTC()
+	ac.UpdatedAt = time.Now().UTC()
+}
@@ -1,14 +1,166 @@
 package models
 
-import "time"
+import (
+	"errors"
+	"time"
+	validator "vaccinationDrive/validators"
+)
+
+const (
+	AppointmentStatusBooked     = "BOOKED"
+	AppointmentStatusCheckedIn  = "CHECKED_IN"
+	AppointmentStatusVaccinated = "VACCINATED"
+	AppointmentStatusNoShow     = "NO_SHOW"
+	AppointmentStatusCancelled  = "CANCELLED"
+)
+
+//AppointmentStatuses lists the valid appointment statuses
+var AppointmentStatuses = map[string]bool{
+	AppointmentStatusBooked:     true,
+	AppointmentStatusCheckedIn:  true,
+	AppointmentStatusVaccinated: true,
+	AppointmentStatusNoShow:     true,
+	AppointmentStatusCancelled:  true,
+}
+
+//appointmentTransitions lists the statuses reachable from each status,
+//vaccinated, no-show and cancelled are final
+var appointmentTransitions = map[string][]string{
+	AppointmentStatusBooked:    {AppointmentStatusCheckedIn, AppointmentStatusNoShow, AppointmentStatusCancelled},
+	AppointmentStatusCheckedIn: {AppointmentStatusVaccinated, AppointmentStatusNoShow},
+}
+
+//CanTransition reports whether an appointment can move from one status to another
+func CanTransition(from, to string) bool {
+	for _, status := range appointmentTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	CancelReasonUnwell              = "UNWELL"
+	CancelReasonTravelling          = "TRAVELLING"
+	CancelReasonScheduleConflict    = "SCHEDULE_CONFLICT"
+	CancelReasonVaccinatedElsewhere = "VACCINATED_ELSEWHERE"
+	CancelReasonOther               = "OTHER"
+)
+
+//CancelReasons lists the accepted cancellation reasons
+var CancelReasons = map[string]bool{
+	CancelReasonUnwell:              true,
+	CancelReasonTravelling:          true,
+	CancelReasonScheduleConflict:    true,
+	CancelReasonVaccinatedElsewhere: true,
+	CancelReasonOther:               true,
+}
 
 type Appointment struct {
-	ID            int64     `json:"id"`
-	BeneficiaryID int64     `json:"beneficiarId"`
-	Date          string    `json:"date"`
-	TimeSlot      string    `json:"timeSlot"`
-	Dose          string    `json:"dose"`
-	VaccineCenter string    `json:"vaccineCenter"`
-	CreatedAt     time.Time `json:"createdAt"`
-	UpdatedAt     time.Time `json:"updatedAt"`
+	ID              int64                    `json:"id"`
+	BeneficiaryID   int64                    `json:"beneficiarId"`
+	Date            string                   `json:"date"`
+	TimeSlot        string                   `json:"timeSlot"`
+	SlotStartsAt    time.Time                `json:"slotStartsAt"`
+	Dose            int                      `json:"dose" sql:",notnull"`
+	VaccineID       int64                    `json:"vaccineId" sql:",notnull,on_delete:RESTRICT"`
+	Vaccine         *Vaccine                 `json:"vaccine,omitempty"`
+	VaccineCenterID int64                    `json:"vaccineCenterId" sql:",notnull,on_delete:RESTRICT"`
+	VaccineCenter   *VaccineCenter           `json:"vaccineCenter,omitempty"`
+	Status          string                   `json:"status" sql:",notnull,default:'BOOKED'"`
+	CancelReason    string                   `json:"cancelReason,omitempty"`
+	CancelRemarks   string                   `json:"cancelRemarks,omitempty"`
+	CheckedInAt     *time.Time               `json:"checkedInAt,omitempty"`
+	VaccinatedAt    *time.Time               `json:"vaccinatedAt,omitempty"`
+	NoShowAt        *time.Time               `json:"noShowAt,omitempty"`
+	CancelledAt     *time.Time               `json:"cancelledAt,omitempty"`
+	Reschedules     []*AppointmentReschedule `json:"reschedules,omitempty"`
+	CreatedAt       time.Time                `json:"createdAt"`
+	UpdatedAt       time.Time                `json:"updatedAt"`
+}
+
+//SetStatus moves the appointment to the status and stamps the transition time
+func (app *Appointment) SetStatus(status string, at time.Time) {
+	app.Status = status
+	app.UpdatedAt = at
+
+	switch status {
+	case AppointmentStatusCheckedIn:
+		app.CheckedInAt = &at
+	case AppointmentStatusVaccinated:
+		app.VaccinatedAt = &at
+	case AppointmentStatusNoShow:
+		app.NoShowAt = &at
+	case AppointmentStatusCancelled:
+		app.CancelledAt = &at
+	}
+}
+
+//AppointmentRequest is the request to book an appointment, the appointment is built
+//from it so that the ID, status and lifecycle fields can't be set by the client
+type AppointmentRequest struct {
+	BeneficiaryID   int64  `json:"beneficiarId"`
+	Date            string `json:"date"`
+	TimeSlot        string `json:"timeSlot"`
+	Dose            int    `json:"dose"`
+	VaccineID       int64  `json:"vaccineId"`
+	VaccineCenterID int64  `json:"vaccineCenterId"`
+}
+
+//Appointment returns the appointment to book for the request
+func (ar AppointmentRequest) Appointment() Appointment {
+	return Appointment{
+		BeneficiaryID:   ar.BeneficiaryID,
+		Date:            ar.Date,
+		TimeSlot:        ar.TimeSlot,
+		Dose:            ar.Dose,
+		VaccineID:       ar.VaccineID,
+		VaccineCenterID: ar.VaccineCenterID,
+	}
+}
+
+//AppointmentReschedule records one move of an appointment to another date, slot or center
+type AppointmentReschedule struct {
+	ID                  int64        `json:"id"`
+	AppointmentID       int64        `json:"appointmentId" sql:",notnull,on_delete:CASCADE"`
+	Appointment         *Appointment `json:"-"`
+	FromDate            string       `json:"fromDate"`
+	FromTimeSlot        string       `json:"fromTimeSlot"`
+	FromVaccineCenterID int64        `json:"fromVaccineCenterId"`
+	ToDate              string       `json:"toDate"`
+	ToTimeSlot          string       `json:"toTimeSlot"`
+	ToVaccineCenterID   int64        `json:"toVaccineCenterId"`
+	CreatedAt           time.Time    `json:"createdAt" sql:",default:now()"`
+}
+
+//AppointmentFilter narrows an appointment listing, zero values are not filtered on
+type AppointmentFilter struct {
+	BeneficiaryID   int64
+	VaccineCenterID int64
+	Date            string
+	Dose            int
+	Status          string
+	ListParams
+}
+
+//AppointmentCancellation is the request to cancel an appointment
+type AppointmentCancellation struct {
+	Reason  string `json:"reason" validate:"required"`
+	Remarks string `json:"remarks"`
+}
+
+//Validate is validation for AppointmentCancellation fields
+func (ac AppointmentCancellation) Validate() (validator.Errors, error) {
+	v := validator.New("AppointmentCancellation")
+
+	if ac.Reason != "" && !CancelReasons[ac.Reason] {
+		v.AddError("reason", errors.New("Reason is not a valid cancellation reason"))
+	}
+
+	if ac.Reason == CancelReasonOther && ac.Remarks == "" {
+		v.AddError("remarks", errors.New("Remarks are required when the reason is OTHER"))
+	}
+
+	return v.Validate(ac)
 }
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestAppointmentRequestIgnoresServerFields(t *testing.T) {
+	body := `{
+		"id": 99,
+		"beneficiarId": 7,
+		"date": "01/06/2021",
+		"timeSlot": "10:00-11:00",
+		"slotStartsAt": "2021-06-01T04:30:00Z",
+		"dose": 1,
+		"vaccineId": 2,
+		"vaccineCenterId": 3,
+		"status": "VACCINATED",
+		"cancelReason": "OTHER",
+		"cancelRemarks": "set by the client",
+		"checkedInAt": "2021-06-01T04:30:00Z",
+		"vaccinatedAt": "2021-06-01T04:45:00Z",
+		"noShowAt": "2021-06-01T05:00:00Z",
+		"cancelledAt": "2021-06-01T05:00:00Z",
+		"reschedules": [{"id": 1}],
+		"createdAt": "2020-01-01T00:00:00Z",
+		"updatedAt": "2020-01-01T00:00:00Z"
+	}`
+
+	req := AppointmentRequest{}
+	if err := json.Unmarshal([]byte(body), &req); err != nil {
+		t.Fatal(err)
+	}
+
+	got := req.Appointment()
+	want := Appointment{
+		BeneficiaryID:   7,
+		Date:            "01/06/2021",
+		TimeSlot:        "10:00-11:00",
+		Dose:            1,
+		VaccineID:       2,
+		VaccineCenterID: 3,
+	}
+
+	if got.ID != 0 || got.Status != "" || got.CancelReason != "" || got.CancelRemarks != "" ||
+		got.CheckedInAt != nil || got.VaccinatedAt != nil || got.NoShowAt != nil || got.CancelledAt != nil ||
+		got.Reschedules != nil || !got.SlotStartsAt.IsZero() || !got.CreatedAt.IsZero() || !got.UpdatedAt.IsZero() {
+		t.Errorf("Appointment kept fields set by the client: %+v", got)
+	}
+
+	if got.BeneficiaryID != want.BeneficiaryID || got.Date != want.Date || got.TimeSlot != want.TimeSlot ||
+		got.Dose != want.Dose || got.VaccineID != want.VaccineID || got.VaccineCenterID != want.VaccineCenterID {
+		t.Errorf("Appointment = %+v, want the booking fields of %+v", got, want)
+	}
+}
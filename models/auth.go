@@ -0,0 +1,161 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	validator "vaccinationDrive/validators"
+)
+
+const (
+	OTPLength = 6
+)
+
+//ErrForbidden is returned when the caller acts on data of another account
+var ErrForbidden = &DomainError{Code: CodeForbidden, Message: "You are not allowed to access this resource"}
+
+//OTP is the last one time password sent to a phone number, only its hash is stored
+type OTP struct {
+	ID              int64      `json:"-"`
+	PhoneNumber     string     `json:"-" sql:",notnull,unique"`
+	CodeHash        string     `json:"-" sql:",notnull"`
+	ExpiresAt       time.Time  `json:"-" sql:",notnull"`
+	Attempts        int        `json:"-" sql:",notnull,default:0"`
+	SendCount       int        `json:"-" sql:",notnull,default:0"` // OTPs sent since WindowStartedAt
+	WindowStartedAt time.Time  `json:"-" sql:",notnull"`
+	LastSentAt      time.Time  `json:"-" sql:",notnull"`
+	VerifiedAt      *time.Time `json:"-"`
+	CreatedAt       time.Time  `json:"-" sql:",default:now()"`
+	UpdatedAt       time.Time  `json:"-" sql:",default:now()"`
+}
+
+// BeforeInsert func
+func (o *OTP) BeforeInsert() {
+	o.CreatedAt = time.Now().UTC()
+	o.UpdatedAt = time.Now().UTC()
+}
+
+//Session is a login of an account, it holds the hash of the refresh token which is
+//replaced on every refresh. Access tokens carry the session ID so that revoking
+//the session on logout also rejects them.
+type Session struct {
+	ID        int64      `json:"-"`
+	TokenHash string     `json:"-" sql:",notnull,unique"`
+	AccountID int64      `json:"-" sql:",notnull,on_delete:CASCADE"`
+	Account   *Account   `json:"-"`
+	ExpiresAt time.Time  `json:"-" sql:",notnull"`
+	RevokedAt *time.Time `json:"-"`
+	CreatedAt time.Time  `json:"-" sql:",default:now()"`
+}
+
+// BeforeInsert func
+func (s *Session) BeforeInsert() {
+	s.CreatedAt = time.Now().UTC()
+}
+
+//TokenPair is handed to the caller on login and on every refresh
+type TokenPair struct {
+	AccessToken      string    `json:"accessToken"`
+	TokenType        string    `json:"tokenType"`
+	ExpiresAt        time.Time `json:"expiresAt"`
+	RefreshToken     string    `json:"refreshToken"`
+	RefreshExpiresAt time.Time `json:"refreshExpiresAt"`
+	AccountID        int64     `json:"accountId"`
+}
+
+//RefreshRequest exchanges the refresh token for a new token pair
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
+}
+
+//Validate is validation for RefreshRequest fields
+func (rr RefreshRequest) Validate() (validator.Errors, error) {
+	v := validator.New("RefreshRequest")
+	return v.Validate(rr)
+}
+
+//Caller is the authenticated account making the request along with its roles
+//and the centers it is assigned to as staff
+type Caller struct {
+	AccountID int64
+	SessionID int64
+	Roles     []string
+	CenterIDs []int64
+}
+
+//HasRole reports whether the caller has any of the roles
+func (c Caller) HasRole(roles ...string) bool {
+	for _, have := range c.Roles {
+		for _, want := range roles {
+			if have == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+//CanAccessAccount reports whether the caller may act on the account and its beneficiaries,
+//beneficiaries only reach their own account
+func (c Caller) CanAccessAccount(accountID int64) bool {
+	if c.HasRole(RoleAdmin) {
+		return true
+	}
+	return c.AccountID != 0 && c.AccountID == accountID
+}
+
+//CanAccessCenter reports whether the caller may manage the appointments of the center,
+//center staff only reach the centers they are assigned to
+func (c Caller) CanAccessCenter(centerID int64) bool {
+	if c.HasRole(RoleAdmin) {
+		return true
+	}
+	if !c.HasRole(RoleCenterStaff) {
+		return false
+	}
+	for _, ID := range c.CenterIDs {
+		if ID == centerID {
+			return true
+		}
+	}
+	return false
+}
+
+//OTPRequest is the request to send an OTP to the phone number
+type OTPRequest struct {
+	PhoneNumber string `json:"phoneNumber" validate:"required"`
+}
+
+//Validate is validation for OTPRequest fields
+func (o OTPRequest) Validate() (validator.Errors, error) {
+	v := validator.New("OTPRequest")
+
+	if o.PhoneNumber != "" {
+		if len(o.PhoneNumber) != 10 {
+			v.AddError("phoneNumber", errors.New("Phone Number should be length of 10 digits"))
+		}
+
+		v.ValidateField("phoneNumber", o.PhoneNumber, []validator.Tag{
+			{Name: "regexp", Fn: validator.Regex, Param: UserMobilePattern},
+		})
+	}
+
+	return v.Validate(o)
+}
+
+//OTPVerification is the OTP entered by the user for the phone number
+type OTPVerification struct {
+	PhoneNumber string `json:"phoneNumber" validate:"required"`
+	OTP         string `json:"otp" validate:"required"`
+}
+
+//Validate is validation for OTPVerification fields
+func (o OTPVerification) Validate() (validator.Errors, error) {
+	v := validator.New("OTPVerification")
+
+	if o.OTP != "" && len(o.OTP) != OTPLength {
+		v.AddError("otp", errors.New("OTP should be length of 6 digits"))
+	}
+
+	return v.Validate(o)
+}
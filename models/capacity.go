@@ -0,0 +1,83 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"vaccinationDrive/utils"
+	validator "vaccinationDrive/validators"
+)
+
+//CenterCapacity holds the quotas published for a vaccine center on a day.
+//SlotQuotas is keyed by time slot and only the listed slots are bookable.
+//DoseQuotas is keyed by dose, a dose without quota is bounded by DailyQuota alone.
+type CenterCapacity struct {
+	ID              int64          `json:"id"`
+	VaccineCenterID int64          `json:"vaccineCenterId" sql:",notnull,unique:center_date,on_delete:CASCADE"`
+	VaccineCenter   *VaccineCenter `json:"-"`
+	Date            string         `json:"date" validate:"required" sql:",notnull,unique:center_date"`
+	DailyQuota      int            `json:"dailyQuota" validate:"min=1" sql:",notnull"`
+	SlotQuotas      map[string]int `json:"slotQuotas" validate:"required"`
+	DoseQuotas      map[int]int    `json:"doseQuotas"`
+	CreatedAt       time.Time      `json:"createdAt" sql:",default:now()"`
+	UpdatedAt       time.Time      `json:"updatedAt" sql:",default:now()"`
+}
+
+//Validate is validation for CenterCapacity fields
+func (c CenterCapacity) Validate() (validator.Errors, error) {
+	v := validator.New("CenterCapacity")
+
+	if c.Date != "" {
+		if _, err := utils.StringddMMyyyyToDate(c.Date); err != nil {
+			v.AddError("date", errors.New("Date should be in DD/MM/YYYY format"))
+		}
+	}
+
+	for slot, quota := range c.SlotQuotas {
+		if quota < 0 || quota > c.DailyQuota {
+			v.AddError(fmt.Sprintf("slotQuotas[%s]", slot), fmt.Errorf("Slot quota should be between 0 and %d", c.DailyQuota))
+		}
+	}
+
+	for dose, quota := range c.DoseQuotas {
+		if quota < 0 || quota > c.DailyQuota {
+			v.AddError(fmt.Sprintf("doseQuotas[%d]", dose), fmt.Errorf("Dose quota should be between 0 and %d", c.DailyQuota))
+		}
+	}
+
+	return v.Validate(c)
+}
+
+//ApplyDefaults fills the daily and slot quotas left out of the request
+func (c *CenterCapacity) ApplyDefaults(dailyQuota int, slotQuotas map[string]int) {
+	if c.DailyQuota == 0 {
+		c.DailyQuota = dailyQuota
+	}
+
+	if len(c.SlotQuotas) == 0 && len(slotQuotas) > 0 {
+		c.SlotQuotas = map[string]int{}
+		for slot, quota := range slotQuotas {
+			c.SlotQuotas[slot] = quota
+		}
+	}
+}
+
+//SlotQuota returns the quota of the time slot, ok is false when the slot is not offered
+func (c CenterCapacity) SlotQuota(slot string) (int, bool) {
+	quota, ok := c.SlotQuotas[slot]
+	return quota, ok
+}
+
+//DoseQuota returns the quota of the dose falling back to the daily quota
+func (c CenterCapacity) DoseQuota(dose int) int {
+	if quota, ok := c.DoseQuotas[dose]; ok {
+		return quota
+	}
+	return c.DailyQuota
+}
+
+// BeforeInsert func
+func (c *CenterCapacity) BeforeInsert() {
+	c.CreatedAt = time.Now().UTC()
+	c.UpdatedAt = time.Now().UTC()
+}
@@ -0,0 +1,56 @@
+package models
+
+import "sync"
+
+//Constraint is how a violated db constraint is shown to the client, Field is the
+//json field the error is attached to
+type Constraint struct {
+	Field   string
+	Message string
+}
+
+var (
+	constraintsMu sync.RWMutex
+
+	//constraints by name, the names are the postgres defaults of the schema
+	constraints = map[string]Constraint{
+		"accounts_phone_number_key":                          {"phoneNumber", "Phone number is already registered"},
+		"otps_phone_number_key":                              {"phoneNumber", "OTP is already requested for the phone number"},
+		"sessions_token_hash_key":                            {"refreshToken", "Session already exists"},
+		"sessions_account_id_fkey":                           {"accountId", "Account not found"},
+		"users_id_type_id_number_hash_key":                   {"idNumber", "ID number is already registered"},
+		"users_account_id_fkey":                              {"accountId", "Account not found"},
+		"appointments_vaccine_id_fkey":                       {"vaccineId", "Vaccine not found"},
+		"appointments_vaccine_center_id_fkey":                {"vaccineCenterId", "Vaccine center not found"},
+		"appointments_beneficiary_id_fkey":                   {"beneficiarId", "Beneficiary not found"},
+		"appointments_beneficiary_id_dose_key":               {"dose", "Dose is already booked"},
+		"appointment_reschedules_appointment_id_fkey":        {"appointmentId", "Appointment not found"},
+		"vaccine_centers_name_district_key":                  {"name", ErrVaccineCenterExists.Message},
+		"vaccines_name_key":                                  {"name", ErrVaccineExists.Message},
+		"center_capacities_vaccine_center_id_date_key":       {"date", "Capacity is already published for the day"},
+		"center_capacities_vaccine_center_id_fkey":           {"vaccineCenterId", "Vaccine center not found"},
+		"account_roles_account_id_role_key":                  {"role", "Account already has the role"},
+		"staff_assignments_account_id_vaccine_center_id_key": {"vaccineCenterId", "Account is already assigned to the center"},
+		"account_roles_account_id_fkey":                      {"accountId", "Account not found"},
+		"staff_assignments_account_id_fkey":                  {"accountId", "Account not found"},
+		"staff_assignments_vaccine_center_id_fkey":           {"vaccineCenterId", "Vaccine center not found"},
+	}
+)
+
+//RegisterConstraint adds or replaces the field and message of a constraint,
+//for constraints added by later migrations
+func RegisterConstraint(name string, c Constraint) {
+	constraintsMu.Lock()
+	defer constraintsMu.Unlock()
+
+	constraints[name] = c
+}
+
+//LookupConstraint returns the field and message of a constraint
+func LookupConstraint(name string) (Constraint, bool) {
+	constraintsMu.RLock()
+	defer constraintsMu.RUnlock()
+
+	c, ok := constraints[name]
+	return c, ok
+}
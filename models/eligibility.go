@@ -0,0 +1,90 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"vaccinationDrive/utils"
+	validator "vaccinationDrive/validators"
+)
+
+const (
+	PriorityGroupHealthcareWorker = "HEALTHCARE_WORKER"
+	PriorityGroupFrontlineWorker  = "FRONTLINE_WORKER"
+	PriorityGroupGeneral          = "GENERAL"
+)
+
+//PriorityGroups lists the valid priority groups of a beneficiary
+var PriorityGroups = map[string]bool{
+	PriorityGroupHealthcareWorker: true,
+	PriorityGroupFrontlineWorker:  true,
+	PriorityGroupGeneral:          true,
+}
+
+//EligibilityPolicy describes who can book during a phase of the drive.
+//A beneficiary is eligible when any active policy in its validity window matches,
+//empty PriorityGroups or VaccineIDs match everyone and MaxAge 0 has no upper bound.
+//Age is computed on AgeReferenceDate, or on the booking date when it is empty.
+type EligibilityPolicy struct {
+	ID                  int64     `json:"id"`
+	Name                string    `json:"name" validate:"required" sql:",notnull"`
+	Phase               string    `json:"phase"`
+	MinAge              int       `json:"minAge" validate:"min=0" sql:",notnull"`
+	MaxAge              int       `json:"maxAge" validate:"min=0" sql:",notnull"`
+	AgeReferenceDate    string    `json:"ageReferenceDate"`
+	PriorityGroups      []string  `json:"priorityGroups"`
+	RequiresComorbidity bool      `json:"requiresComorbidity" sql:",notnull"`
+	VaccineIDs          []int64   `json:"vaccineIds" sql:"vaccine_ids"`
+	ValidFrom           string    `json:"validFrom"`
+	ValidTo             string    `json:"validTo"`
+	IsActive            bool      `json:"isActive" sql:",notnull"`
+	CreatedAt           time.Time `json:"createdAt" sql:",default:now()"`
+	UpdatedAt           time.Time `json:"updatedAt" sql:",default:now()"`
+}
+
+//Validate is validation for EligibilityPolicy fields
+func (ep EligibilityPolicy) Validate() (validator.Errors, error) {
+	v := validator.New("EligibilityPolicy")
+
+	if ep.MaxAge > 0 && ep.MaxAge < ep.MinAge {
+		v.AddError("maxAge", errors.New("Max age should not be less than min age"))
+	}
+
+	dates := map[string]string{
+		"ageReferenceDate": ep.AgeReferenceDate,
+		"validFrom":        ep.ValidFrom,
+		"validTo":          ep.ValidTo,
+	}
+	for field, date := range dates {
+		if date == "" {
+			continue
+		}
+		if _, err := utils.StringddMMyyyyToDate(date); err != nil {
+			v.AddError(field, errors.New("Date should be in DD/MM/YYYY format"))
+		}
+	}
+
+	for i, group := range ep.PriorityGroups {
+		if !PriorityGroups[group] {
+			v.AddError(fmt.Sprintf("priorityGroups[%d]", i), fmt.Errorf("%s is not a valid priority group", group))
+		}
+	}
+
+	return v.Validate(ep)
+}
+
+//Normalize trims the free text fields of the policy
+func (ep *EligibilityPolicy) Normalize() {
+	ep.Name = strings.TrimSpace(ep.Name)
+	ep.Phase = strings.TrimSpace(ep.Phase)
+	for i, group := range ep.PriorityGroups {
+		ep.PriorityGroups[i] = strings.ToUpper(strings.TrimSpace(group))
+	}
+}
+
+// BeforeInsert func
+func (ep *EligibilityPolicy) BeforeInsert() {
+	ep.CreatedAt = time.Now().UTC()
+	ep.UpdatedAt = time.Now().UTC()
+}
@@ -1,7 +1,10 @@
 package models
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"strings"
 
@@ -10,10 +13,12 @@ import (
 
 // ErrorData struct
 type ErrorData struct {
-	Message string `json:"message"`
-	Err     error  `json:"error"`
-	IsDbErr bool   `json:"-"`
-	Code    int    `json:"code"`
+	Message   string `json:"message"`
+	Err       error  `json:"error"`
+	IsDbErr   bool   `json:"-"`
+	Code      int    `json:"code"`
+	Field     string `json:"field,omitempty"` // json field of the violated constraint
+	Retryable bool   `json:"-"`               // the same request may succeed if sent again
 }
 
 func (e ErrorData) Error() string {
@@ -45,48 +50,162 @@ func (e *ErrorData) Set() {
 	}
 }
 
-//SetDbError set db error in model
+//SetDbError translates the db error into a status and a message the client can
+//be shown. Errors that are not expected from a valid request are 500s and their
+//details are left to the logs.
 func (e *ErrorData) SetDbError() {
 
-	switch e.Err {
-	case pg.ErrNoRows:
+	switch {
+	case e.Err == pg.ErrNoRows:
 		e.Code = http.StatusNotFound
-		e.Message = fmt.Sprintf("Data not found")
+		e.Message = "Data not found"
+		return
+	case isConnError(e.Err):
+		e.Code = http.StatusServiceUnavailable
+		e.Message = "Database is unavailable, please try again"
+		e.Retryable = true
 		return
 	}
 
-	dbErr, ok := (e.Err).(pg.Error)
-
-	if !ok {
-		e.Code = http.StatusBadRequest
-		if e.Message == "" {
-			e.Message = fmt.Sprintf("DB Error: %s", e.Stack())
-		}
+	var dbErr pg.Error
+	if !errors.As(e.Err, &dbErr) {
+		e.Code = http.StatusInternalServerError
+		e.Message = "Database error"
 		return
 	}
 
-	switch dbErr.Field('C') {
-	case "23505":
+	sqlState := dbErr.Field('C')
+	constraint, known := LookupConstraint(dbErr.Field('n'))
+	if known {
+		e.Field = constraint.Field
+	}
+
+	switch {
+	case sqlState == pgUniqueViolation:
+		e.Code = http.StatusConflict
+		e.Message = constraintMessage(constraint, known, "Record already exists")
+
+	case sqlState == pgForeignKeyViolation:
+		//the same code is used for both sides of the key, the message tells them apart
+		if strings.HasPrefix(dbErr.Field('M'), "update or delete") {
+			e.Code = http.StatusConflict
+			e.Message = "Record is in use and cannot be removed"
+			e.Field = ""
+			return
+		}
+		e.Code = http.StatusBadRequest
+		e.Message = constraintMessage(constraint, known, "Referenced record does not exist")
+
+	case sqlState == pgCheckViolation, sqlState == pgExclusionViolation:
+		e.Code = http.StatusBadRequest
+		e.Message = constraintMessage(constraint, known, "Value is not allowed")
+
+	case sqlState == pgNotNullViolation:
+		e.Field = jsonName(dbErr.Field('c'))
+		e.Code = http.StatusBadRequest
+		e.Message = fmt.Sprintf("%s is required", e.Field)
+
+	case sqlState == pgSerializationFailure:
+		e.Code = http.StatusConflict
+		e.Message = "Request conflicted with another one, please try again"
+		e.Retryable = true
+
+	case sqlState == pgDeadlockDetected, sqlState == pgLockNotAvailable:
+		e.Code = http.StatusServiceUnavailable
+		e.Message = "Database is busy, please try again"
+		e.Retryable = true
+
+	case sqlState == pgQueryCanceled:
+		e.Code = http.StatusServiceUnavailable
+		e.Message = "Request took too long, please try again"
+		e.Retryable = true
+
+	case strings.HasPrefix(sqlState, pgClassConnection), sqlState == pgTooManyConnections,
+		sqlState == pgAdminShutdown, sqlState == pgCannotConnectNow:
+		e.Code = http.StatusServiceUnavailable
+		e.Message = "Database is unavailable, please try again"
+		e.Retryable = true
+
+	case strings.HasPrefix(sqlState, pgClassDataException):
 		e.Code = http.StatusBadRequest
-		e.Message = dbErr.Field('D')
-
-		//get unqiue field
-		key := strings.Split(e.Message, "=")
-		keyIndex := strings.Index(key[0], " ")
-		str := key[0]
-		// log.Printf("error", str[keyIndex: len(str)-1])
-		uniqueField := str[keyIndex : len(str)-1]
-		uniqueFieldName := uniqueField + ") already exists"
-		if uniqueFieldName != "" {
-			e.Message = uniqueFieldName
+		e.Message = "Invalid value"
+		if column := dbErr.Field('c'); column != "" {
+			e.Field = jsonName(column)
+			e.Message = fmt.Sprintf("Invalid value for %s", e.Field)
 		}
 
 	default:
-		e.Code = http.StatusBadRequest
-		if e.Message == "" {
-			e.Message = fmt.Sprintf("DB Error: %s", e.Stack())
+		e.Code = http.StatusInternalServerError
+		e.Message = "Database error"
+	}
+}
+
+//IsRetryable reports whether err is a db error the same request may not hit
+//again, Eg. a serialization failure or a dropped connection
+func IsRetryable(err error) bool {
+	e := ErrorData{Err: err, IsDbErr: true}
+	e.Set()
+	return e.Retryable
+}
+
+//IsDbError reports whether err comes from the db or the connection to it
+func IsDbError(err error) bool {
+	var dbErr pg.Error
+	return err == pg.ErrNoRows || err == pg.ErrMultiRows || errors.As(err, &dbErr) || isConnError(err)
+}
+
+//SQLSTATE codes, https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	pgUniqueViolation      = "23505"
+	pgForeignKeyViolation  = "23503"
+	pgCheckViolation       = "23514"
+	pgNotNullViolation     = "23502"
+	pgExclusionViolation   = "23P01"
+	pgSerializationFailure = "40001"
+	pgDeadlockDetected     = "40P01"
+	pgLockNotAvailable     = "55P03"
+	pgQueryCanceled        = "57014"
+	pgAdminShutdown        = "57P01"
+	pgCannotConnectNow     = "57P03"
+	pgTooManyConnections   = "53300"
+	pgClassConnection      = "08"
+	pgClassDataException   = "22"
+)
+
+//poolErrors are returned by the connection pool of go-pg, which does not export them
+var poolErrors = map[string]bool{
+	"pg: database is closed":      true,
+	"pg: connection pool timeout": true,
+}
+
+//isConnError reports whether the connection failed before postgres answered
+func isConnError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if err == io.EOF || err == io.ErrUnexpectedEOF || poolErrors[err.Error()] {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+func constraintMessage(c Constraint, known bool, fallback string) string {
+	if known && c.Message != "" {
+		return c.Message
+	}
+	return fallback
+}
+
+//jsonName turns a column name into its json field name, Eg. vaccine_center_id to vaccineCenterId
+func jsonName(column string) string {
+	parts := strings.Split(column, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
 		}
 	}
+	return strings.Join(parts, "")
 }
 
 // Stack func
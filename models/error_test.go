@@ -0,0 +1,92 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/go-pg/pg"
+)
+
+//fakePgError carries the fields of a postgres error like pg.Error,
+//C is the SQLSTATE, n the constraint, c the column and M the message
+type fakePgError map[byte]string
+
+func (e fakePgError) Error() string            { return "ERROR #" + e['C'] + " " + e['M'] }
+func (e fakePgError) Field(k byte) string      { return e[k] }
+func (e fakePgError) IntegrityViolation() bool { return e['C'][:2] == "23" }
+
+func TestSetDbError(t *testing.T) {
+	RegisterConstraint("test_widgets_code_key", Constraint{"code", "Widget code is taken"})
+
+	tests := []struct {
+		name      string
+		err       error
+		code      int
+		message   string
+		field     string
+		retryable bool
+	}{
+		{"no rows", pg.ErrNoRows, http.StatusNotFound, "Data not found", "", false},
+		{"connection lost", io.ErrUnexpectedEOF, http.StatusServiceUnavailable, "Database is unavailable, please try again", "", true},
+		{"not a postgres error", errors.New("pg: Model(nil)"), http.StatusInternalServerError, "Database error", "", false},
+
+		{"unique violation", fakePgError{'C': "23505", 'n': "users_id_type_id_number_hash_key"},
+			http.StatusConflict, "ID number is already registered", "idNumber", false},
+		{"wrapped unique violation", fmt.Errorf("register: %w", fakePgError{'C': "23505", 'n': "accounts_phone_number_key"}),
+			http.StatusConflict, "Phone number is already registered", "phoneNumber", false},
+		{"unique violation of a registered constraint", fakePgError{'C': "23505", 'n': "test_widgets_code_key"},
+			http.StatusConflict, "Widget code is taken", "code", false},
+		{"unique violation of an unknown constraint", fakePgError{'C': "23505", 'n': "widgets_name_key"},
+			http.StatusConflict, "Record already exists", "", false},
+
+		{"foreign key violation", fakePgError{'C': "23503", 'n': "appointments_vaccine_id_fkey",
+			'M': `insert or update on table "appointments" violates foreign key constraint "appointments_vaccine_id_fkey"`},
+			http.StatusBadRequest, "Vaccine not found", "vaccineId", false},
+		{"foreign key violation of an unknown constraint", fakePgError{'C': "23503", 'n': "widgets_owner_id_fkey",
+			'M': `insert or update on table "widgets" violates foreign key constraint "widgets_owner_id_fkey"`},
+			http.StatusBadRequest, "Referenced record does not exist", "", false},
+		{"delete of a referenced row", fakePgError{'C': "23503", 'n': "appointments_vaccine_id_fkey",
+			'M': `update or delete on table "vaccines" violates foreign key constraint "appointments_vaccine_id_fkey"`},
+			http.StatusConflict, "Record is in use and cannot be removed", "", false},
+
+		{"check violation", fakePgError{'C': "23514", 'n': "widgets_size_check"},
+			http.StatusBadRequest, "Value is not allowed", "", false},
+		{"not null violation", fakePgError{'C': "23502", 'c': "vaccine_center_id"},
+			http.StatusBadRequest, "vaccineCenterId is required", "vaccineCenterId", false},
+
+		{"invalid text representation", fakePgError{'C': "22P02"},
+			http.StatusBadRequest, "Invalid value", "", false},
+		{"invalid value of a column", fakePgError{'C': "22007", 'c': "slot_starts_at"},
+			http.StatusBadRequest, "Invalid value for slotStartsAt", "slotStartsAt", false},
+
+		{"serialization failure", fakePgError{'C': "40001"},
+			http.StatusConflict, "Request conflicted with another one, please try again", "", true},
+		{"deadlock", fakePgError{'C': "40P01"},
+			http.StatusServiceUnavailable, "Database is busy, please try again", "", true},
+		{"query canceled", fakePgError{'C': "57014"},
+			http.StatusServiceUnavailable, "Request took too long, please try again", "", true},
+		{"connection exception", fakePgError{'C': "08006"},
+			http.StatusServiceUnavailable, "Database is unavailable, please try again", "", true},
+		{"unknown sqlstate", fakePgError{'C': "42P01"},
+			http.StatusInternalServerError, "Database error", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := ErrorData{Err: tt.err, IsDbErr: true}
+			e.Set()
+
+			if e.Code != tt.code || e.Message != tt.message || e.Field != tt.field || e.Retryable != tt.retryable {
+				t.Errorf("SetDbError = {%d %q %q %v}, want {%d %q %q %v}",
+					e.Code, e.Message, e.Field, e.Retryable, tt.code, tt.message, tt.field, tt.retryable)
+			}
+
+			if IsRetryable(tt.err) != tt.retryable {
+				t.Errorf("IsRetryable = %v, want %v", !tt.retryable, tt.retryable)
+			}
+		})
+	}
+}
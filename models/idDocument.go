@@ -0,0 +1,22 @@
+package models
+
+import validator "vaccinationDrive/validators"
+
+const (
+	IDTypeAadhaar         = "AADHAAR"
+	IDTypePAN             = "PAN"
+	IDTypePassport        = "PASSPORT"
+	IDTypeVoterID         = "VOTER_ID"
+	IDTypeDrivingLicence  = "DRIVING_LICENCE"
+	IDTypePensionPassbook = "PENSION_PASSBOOK"
+)
+
+//IDTypes maps the photo ID documents accepted at registration to the validator of their number
+var IDTypes = map[string]validator.Tag{
+	IDTypeAadhaar:         {Name: "aadhaar", Fn: validator.Aadhaar},
+	IDTypePAN:             {Name: "pan", Fn: validator.PAN},
+	IDTypePassport:        {Name: "passport", Fn: validator.Passport},
+	IDTypeVoterID:         {Name: "voterId", Fn: validator.VoterID},
+	IDTypeDrivingLicence:  {Name: "drivingLicence", Fn: validator.DrivingLicence},
+	IDTypePensionPassbook: {Name: "pensionPassbook", Fn: validator.PensionPassbook},
+}
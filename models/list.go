@@ -0,0 +1,39 @@
+package models
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+
+	SortAsc  = "asc"
+	SortDesc = "desc"
+)
+
+//ListParams holds the pagination and sorting of a listing request
+type ListParams struct {
+	Page  int    `json:"page"`
+	Limit int    `json:"limit"`
+	Sort  string `json:"sort"`
+}
+
+//Offset returns the number of rows to skip for the page
+func (lp ListParams) Offset() int {
+	return (lp.Page - 1) * lp.Limit
+}
+
+//ListResponse is the envelope of every listing response
+type ListResponse struct {
+	Data  interface{} `json:"data"`
+	Total int         `json:"total"`
+	Page  int         `json:"page"`
+	Limit int         `json:"limit"`
+}
+
+//NewListResponse wraps the page data with the total count
+func NewListResponse(data interface{}, total int, lp ListParams) ListResponse {
+	return ListResponse{
+		Data:  data,
+		Total: total,
+		Page:  lp.Page,
+		Limit: lp.Limit,
+	}
+}
@@ -0,0 +1,217 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	validator "vaccinationDrive/validators"
+)
+
+//Problem codes are part of the API, clients match on them so they are never renamed
+const (
+	CodeValidationFailed   = "VALIDATION_FAILED"
+	CodeBadRequest         = "BAD_REQUEST"
+	CodeUnauthorized       = "UNAUTHORIZED"
+	CodeForbidden          = "FORBIDDEN"
+	CodeNotFound           = "NOT_FOUND"
+	CodeConflict           = "CONFLICT"
+	CodeSlotFull           = "SLOT_FULL"
+	CodeNotEligible        = "NOT_ELIGIBLE"
+	CodeDoseIntervalNotMet = "DOSE_INTERVAL_NOT_MET"
+	CodeTooManyRequests    = "TOO_MANY_REQUESTS"
+	CodeUnavailable        = "SERVICE_UNAVAILABLE"
+	CodeInternal           = "INTERNAL_ERROR"
+)
+
+//codeStatus is the http status of each problem code
+var codeStatus = map[string]int{
+	CodeValidationFailed:   http.StatusBadRequest,
+	CodeBadRequest:         http.StatusBadRequest,
+	CodeUnauthorized:       http.StatusUnauthorized,
+	CodeForbidden:          http.StatusForbidden,
+	CodeNotFound:           http.StatusNotFound,
+	CodeConflict:           http.StatusConflict,
+	CodeSlotFull:           http.StatusConflict,
+	CodeNotEligible:        http.StatusUnprocessableEntity,
+	CodeDoseIntervalNotMet: http.StatusUnprocessableEntity,
+	CodeTooManyRequests:    http.StatusTooManyRequests,
+	CodeUnavailable:        http.StatusServiceUnavailable,
+	CodeInternal:           http.StatusInternalServerError,
+}
+
+//statusCode is the problem code of a bare http status
+var statusCode = map[int]string{
+	http.StatusBadRequest:          CodeBadRequest,
+	http.StatusUnauthorized:        CodeUnauthorized,
+	http.StatusForbidden:           CodeForbidden,
+	http.StatusNotFound:            CodeNotFound,
+	http.StatusConflict:            CodeConflict,
+	http.StatusTooManyRequests:     CodeTooManyRequests,
+	http.StatusServiceUnavailable:  CodeUnavailable,
+	http.StatusInternalServerError: CodeInternal,
+}
+
+//DomainError is a business rule the request broke, Code tells the rules apart
+type DomainError struct {
+	Code    string
+	Message string
+	Fields  validator.Errors
+}
+
+func (e *DomainError) Error() string {
+	return e.Message
+}
+
+//Is matches domain errors by code, Eg. errors.Is(err, ErrSlotFull)
+func (e *DomainError) Is(target error) bool {
+	t, ok := target.(*DomainError)
+	return ok && t.Code == e.Code
+}
+
+//Status returns the http status of the error
+func (e *DomainError) Status() int {
+	if status, ok := codeStatus[e.Code]; ok {
+		return status
+	}
+	return http.StatusBadRequest
+}
+
+//Sentinels to match domain errors with errors.Is
+var (
+	ErrSlotFull           = &DomainError{Code: CodeSlotFull, Message: "Slot is full"}
+	ErrNotEligible        = &DomainError{Code: CodeNotEligible, Message: "Not eligible"}
+	ErrDoseIntervalNotMet = &DomainError{Code: CodeDoseIntervalNotMet, Message: "Dose interval not met"}
+	ErrNotFound           = &DomainError{Code: CodeNotFound, Message: "Not found"}
+	ErrConflict           = &DomainError{Code: CodeConflict, Message: "Conflict"}
+)
+
+func newDomainError(code, format string, args ...interface{}) *DomainError {
+	return &DomainError{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+//BadRequest is a request that can't be served as it is
+func BadRequest(format string, args ...interface{}) error {
+	return newDomainError(CodeBadRequest, format, args...)
+}
+
+//InvalidParam is a bad request caused by one query or path param, the message is
+//attached to the param like a field error
+func InvalidParam(param, format string, args ...interface{}) error {
+	e := newDomainError(CodeBadRequest, format, args...)
+	e.Fields = validator.Errors{param: errors.New(e.Message)}
+	return e
+}
+
+//Unauthorized is a request without valid credentials
+func Unauthorized(format string, args ...interface{}) error {
+	return newDomainError(CodeUnauthorized, format, args...)
+}
+
+//NotFound is a missing resource
+func NotFound(format string, args ...interface{}) error {
+	return newDomainError(CodeNotFound, format, args...)
+}
+
+//Conflict is a request clashing with the current state of a resource
+func Conflict(format string, args ...interface{}) error {
+	return newDomainError(CodeConflict, format, args...)
+}
+
+//SlotFull is a booking for a slot, day or dose without quota left
+func SlotFull(format string, args ...interface{}) error {
+	return newDomainError(CodeSlotFull, format, args...)
+}
+
+//NotEligible is a beneficiary the vaccine or the eligibility policies don't allow
+func NotEligible(format string, args ...interface{}) error {
+	return newDomainError(CodeNotEligible, format, args...)
+}
+
+//DoseIntervalNotMet is a dose booked outside the window after the previous dose
+func DoseIntervalNotMet(format string, args ...interface{}) error {
+	return newDomainError(CodeDoseIntervalNotMet, format, args...)
+}
+
+//TooManyRequests is a request throttled by a limit
+func TooManyRequests(format string, args ...interface{}) error {
+	return newDomainError(CodeTooManyRequests, format, args...)
+}
+
+//Unavailable is a dependency that can't serve the request right now
+func Unavailable(format string, args ...interface{}) error {
+	return newDomainError(CodeUnavailable, format, args...)
+}
+
+//ValidationFailed carries the field errors of a request body
+func ValidationFailed(errs validator.Errors) error {
+	return &DomainError{Code: CodeValidationFailed, Message: "Validation Error(s)", Fields: errs}
+}
+
+//Problem is the RFC 7807 body of every error response. Code is the stable machine
+//readable code and Errors holds the field errors of a failed validation.
+type Problem struct {
+	Type     string           `json:"type"`
+	Title    string           `json:"title"`
+	Status   int              `json:"status"`
+	Detail   string           `json:"detail,omitempty"`
+	Instance string           `json:"instance,omitempty"`
+	Code     string           `json:"code"`
+	Errors   validator.Errors `json:"errors,omitempty"`
+}
+
+//NewProblem builds the problem of a bare http status
+func NewProblem(status int, detail string) Problem {
+	code, ok := statusCode[status]
+	if !ok {
+		code = CodeBadRequest
+		if status >= http.StatusInternalServerError {
+			code = CodeInternal
+		}
+	}
+	return problem(code, status, detail, nil)
+}
+
+//ProblemFrom builds the problem of an error returned by a service or a dao. Errors
+//that are not domain or db errors are internal, their message is not exposed.
+func ProblemFrom(err error) Problem {
+	var de *DomainError
+	if errors.As(err, &de) {
+		return problem(de.Code, de.Status(), de.Message, de.Fields)
+	}
+
+	switch ed := err.(type) {
+	case *ErrorData:
+		return ed.problem()
+	case ErrorData:
+		return ed.problem()
+	}
+
+	if IsDbError(err) {
+		ed := &ErrorData{Err: err, IsDbErr: true}
+		return ed.problem()
+	}
+
+	return NewProblem(http.StatusInternalServerError, "Something went wrong, please try again")
+}
+
+//problem attaches the message to the field of the violated constraint, if known
+func (e *ErrorData) problem() Problem {
+	e.Set()
+	p := NewProblem(e.Code, e.Message)
+	if e.Field != "" {
+		p.Errors = validator.Errors{e.Field: errors.New(e.Message)}
+	}
+	return p
+}
+
+func problem(code string, status int, detail string, errs validator.Errors) Problem {
+	return Problem{
+		Type:   "urn:vaccination-drive:problem:" + strings.ToLower(strings.Replace(code, "_", "-", -1)),
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+		Errors: errs,
+	}
+}
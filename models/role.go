@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+const (
+	RoleAdmin       = "ADMIN"
+	RoleCenterStaff = "CENTER_STAFF"
+	RoleBeneficiary = "BENEFICIARY"
+)
+
+//Roles which can be granted to an account, every account is a beneficiary without a grant
+var Roles = map[string]bool{
+	RoleAdmin:       true,
+	RoleCenterStaff: true,
+}
+
+//AccountRole grants a role to an account
+type AccountRole struct {
+	ID        int64     `json:"-"`
+	AccountID int64     `json:"accountId" sql:",notnull,unique:account_role,on_delete:CASCADE"`
+	Role      string    `json:"role" sql:",notnull,unique:account_role"`
+	CreatedAt time.Time `json:"createdAt" sql:",default:now()"`
+}
+
+// BeforeInsert func
+func (ar *AccountRole) BeforeInsert() {
+	ar.CreatedAt = time.Now().UTC()
+}
+
+//StaffAssignment assigns a center staff account to a vaccine center
+type StaffAssignment struct {
+	ID              int64     `json:"-"`
+	AccountID       int64     `json:"accountId" sql:",notnull,unique:account_center,on_delete:CASCADE"`
+	VaccineCenterID int64     `json:"vaccineCenterId" sql:",notnull,unique:account_center,on_delete:CASCADE"`
+	CreatedAt       time.Time `json:"createdAt" sql:",default:now()"`
+}
+
+// BeforeInsert func
+func (sa *StaffAssignment) BeforeInsert() {
+	sa.CreatedAt = time.Now().UTC()
+}
+
+//AccountAccess lists the roles of the account and the centers it is assigned to
+type AccountAccess struct {
+	AccountID int64    `json:"accountId"`
+	Roles     []string `json:"roles"`
+	CenterIDs []int64  `json:"centerIds"`
+}
+
+//StaffAssignmentRequest is the request to assign an account to a center
+type StaffAssignmentRequest struct {
+	AccountID int64 `json:"accountId"`
+}
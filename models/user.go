@@ -1,9 +1,13 @@
 package models
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
-	"vaccinationDrive/dbcon"
+	"vaccinationDrive/internals/pii"
+	"vaccinationDrive/utils"
 	validator "vaccinationDrive/validators"
 )
 
@@ -12,33 +16,57 @@ const (
 )
 
 type User struct {
-	ID          int64     `json:"id"`
-	Name        string    `json:"name"`
-	DOB         string    `json:"dob"`
-	Age         float64   `json:"age"`
-	AadharNo    string    `json:"aadharNo" validate:"required" sql:",notnull"`
-	PhoneNumber string    `json:"phoneNumber" validate:"required" sql:",notnull"`
-	CreatedAt   time.Time `json:"-" sql:",default:now()"`
-	UpdatedAt   time.Time `json:"-" sql:",default:now()"`
+	ID                int64      `json:"id"`
+	Name              string     `json:"name"`
+	DOB               string     `json:"dob" validate:"required"`
+	Age               float64    `json:"age"`
+	IDType            string     `json:"idType" validate:"required" sql:",notnull,unique:id_document"`
+	IDNumber          string     `json:"idNumber" validate:"required" sql:"-"` // plaintext, only held while registering
+	IDNumberEncrypted string     `json:"-" sql:",notnull"`
+	IDNumberHash      string     `json:"-" sql:",notnull,unique:id_document"`
+	IDNumberLast4     string     `json:"-" sql:"id_number_last4,notnull"`
+	AadharNo          string     `json:"aadharNo,omitempty" sql:"-"` // deprecated, read as an AADHAAR document
+	PhoneNumber       string     `json:"phoneNumber" validate:"required" sql:",notnull"`
+	AccountID         int64      `json:"accountId" sql:",notnull,on_delete:RESTRICT"`
+	Account           *Account   `json:"-"`
+	PriorityGroup     string     `json:"priorityGroup"`
+	Comorbidities     []string   `json:"comorbidities"`
+	RemovedAt         *time.Time `json:"-"`
+	CreatedAt         time.Time  `json:"-" sql:",default:now()"`
+	UpdatedAt         time.Time  `json:"-" sql:",default:now()"`
 }
 
-//Validate is validation for User fields
+//ErrIDNumberRegistered is the registration of an ID document registered before
+var ErrIDNumberRegistered = &DomainError{
+	Code:    CodeConflict,
+	Message: "ID number is already registered",
+	Fields:  validator.Errors{"idNumber": errors.New("ID number is already registered")},
+}
+
+//Validate is validation for User fields, the uniqueness of the ID document is checked
+//when registering
 func (us User) Validate() (validator.Errors, error) {
-	db := dbcon.Get()
 	v := validator.New("User")
 
-	count, _ := db.Model(&us).Where("aadhar_no = ?", us.AadharNo).Count()
-	if count > 0 {
-		v.AddError("aadharNo", errors.New("AadharNo is already present"))
-
+	if us.IDType != "" {
+		if tag, ok := IDTypes[us.IDType]; !ok {
+			v.AddError("idType", errors.New("ID type is not a valid photo ID document"))
+		} else if us.IDNumber != "" {
+			v.ValidateField("idNumber", us.IDNumber, []validator.Tag{tag})
+		}
 	}
 
-	//aadhar no length
-	if us.AadharNo != "" {
-		if aadharNumberLength := len(us.AadharNo); aadharNumberLength != 15 {
-			v.AddError("aadharNo", errors.New("AadharNo should be length of 15 digits"))
+	if us.DOB != "" {
+		dob, err := utils.StringddMMyyyyToDate(us.DOB)
+		if err != nil {
+			v.AddError("dob", errors.New("DOB should be in DD/MM/YYYY format"))
+		} else if dob.After(time.Now()) {
+			v.AddError("dob", errors.New("DOB should not be in the future"))
 		}
+	}
 
+	if us.PriorityGroup != "" && !PriorityGroups[us.PriorityGroup] {
+		v.AddError("priorityGroup", errors.New("Priority group is not valid"))
 	}
 
 	//mobile no length
@@ -56,6 +84,74 @@ func (us User) Validate() (validator.Errors, error) {
 	return v.Validate(us)
 }
 
+//Normalize reads the deprecated aadharNo as an AADHAAR document and strips the grouping
+//of the ID number so that it is stored and compared in one form
+func (us *User) Normalize() {
+	if us.IDType == "" && us.IDNumber == "" && us.AadharNo != "" {
+		us.IDType = IDTypeAadhaar
+		us.IDNumber = us.AadharNo
+	}
+	us.AadharNo = ""
+
+	us.IDType = strings.ToUpper(strings.TrimSpace(us.IDType))
+	us.IDNumber = validator.NormalizeIDNumber(us.IDNumber)
+}
+
+//SealIDNumber encrypts the ID number into the stored columns and drops the plaintext
+func (us *User) SealIDNumber(kr *pii.Keyring) error {
+	if len(us.IDNumber) < 4 {
+		return errors.New("ID number is not valid")
+	}
+
+	enc, err := kr.Encrypt(us.IDNumber)
+	if err != nil {
+		return err
+	}
+
+	us.IDNumberEncrypted = enc
+	us.IDNumberHash = kr.Hash(us.IDNumber)
+	us.IDNumberLast4 = us.IDNumber[len(us.IDNumber)-4:]
+	us.IDNumber = ""
+	return nil
+}
+
+//MaskedIDNumber is the only form of the ID number shown in responses and logs
+func (us User) MaskedIDNumber() string {
+	last4 := us.IDNumberLast4
+	if last4 == "" && len(us.IDNumber) >= 4 {
+		last4 = us.IDNumber[len(us.IDNumber)-4:]
+	}
+
+	if us.IDType == IDTypeAadhaar {
+		return pii.MaskAadhaar(last4)
+	}
+	return pii.Mask(last4)
+}
+
+//MarshalJSON masks the ID number
+func (us User) MarshalJSON() ([]byte, error) {
+	type user User
+	u := user(us)
+	u.IDNumber = us.MaskedIDNumber()
+	u.AadharNo = ""
+	return json.Marshal(u)
+}
+
+//String masks the ID number when the user is logged
+func (us User) String() string {
+	return fmt.Sprintf("{ID:%d Name:%s IDType:%s IDNumber:%s AccountID:%d}", us.ID, us.Name, us.IDType, us.MaskedIDNumber(), us.AccountID)
+}
+
+//DateOfBirth returns the parsed DOB of the user
+func (us User) DateOfBirth() (time.Time, error) {
+	return utils.StringddMMyyyyToDate(us.DOB)
+}
+
+//IsMember reports whether the user is still a member of its account
+func (us User) IsMember() bool {
+	return us.RemovedAt == nil
+}
+
 // BeforeInsert func
 func (us *User) BeforeInsert() {
 	us.CreatedAt = time.Now().UTC()
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	validator "vaccinationDrive/validators"
+)
+
+//DoseInterval is the window, in days after the previous dose was administered,
+//within which Dose can be taken. MaxDays 0 means the window has no upper bound.
+type DoseInterval struct {
+	Dose    int `json:"dose"`
+	MinDays int `json:"minDays"`
+	MaxDays int `json:"maxDays"`
+}
+
+type Vaccine struct {
+	ID            int64          `json:"id"`
+	Name          string         `json:"name" validate:"required" sql:",notnull"`
+	Manufacturer  string         `json:"manufacturer" validate:"required" sql:",notnull"`
+	NumberOfDoses int            `json:"numberOfDoses" validate:"min=1" sql:",notnull"`
+	DoseIntervals []DoseInterval `json:"doseIntervals"`
+	MinAge        int            `json:"minAge" validate:"min=0" sql:",notnull"`
+	MaxAge        int            `json:"maxAge" validate:"min=0" sql:",notnull"`
+	IsActive      bool           `json:"isActive" sql:",notnull"`
+	CreatedAt     time.Time      `json:"createdAt" sql:",default:now()"`
+	UpdatedAt     time.Time      `json:"updatedAt" sql:",default:now()"`
+}
+
+//ErrVaccineExists is a vaccine named like another one
+var ErrVaccineExists = &DomainError{
+	Code:    CodeConflict,
+	Message: "Vaccine is already present",
+	Fields:  validator.Errors{"name": errors.New("Vaccine is already present")},
+}
+
+//Validate is validation for Vaccine fields, the uniqueness of the name is checked
+//when saving
+func (vc Vaccine) Validate() (validator.Errors, error) {
+	v := validator.New("Vaccine")
+
+	if vc.MaxAge > 0 && vc.MaxAge < vc.MinAge {
+		v.AddError("maxAge", errors.New("Max age should not be less than min age"))
+	}
+
+	if vc.NumberOfDoses > 0 && len(vc.DoseIntervals) != vc.NumberOfDoses-1 {
+		v.AddError("doseIntervals", fmt.Errorf("Dose intervals should be given for doses 2 to %d", vc.NumberOfDoses))
+	}
+
+	for i, interval := range vc.DoseIntervals {
+		field := fmt.Sprintf("doseIntervals[%d]", i)
+		switch {
+		case interval.Dose != i+2:
+			v.AddError(field, fmt.Errorf("Dose should be %d", i+2))
+		case interval.MinDays < 0:
+			v.AddError(field, errors.New("Min days should not be negative"))
+		case interval.MaxDays != 0 && interval.MaxDays < interval.MinDays:
+			v.AddError(field, errors.New("Max days should not be less than min days"))
+		}
+	}
+
+	return v.Validate(vc)
+}
+
+//Normalize trims the free text fields of the vaccine
+func (vc *Vaccine) Normalize() {
+	vc.Name = strings.Join(strings.Fields(vc.Name), " ")
+	vc.Manufacturer = strings.Join(strings.Fields(vc.Manufacturer), " ")
+}
+
+//DoseInterval returns the interval leading to the dose, ok is false for the first dose
+func (vc Vaccine) DoseInterval(dose int) (DoseInterval, bool) {
+	for _, interval := range vc.DoseIntervals {
+		if interval.Dose == dose {
+			return interval, true
+		}
+	}
+	return DoseInterval{}, false
+}
+
+// BeforeInsert func
+func (vc *Vaccine) BeforeInsert() {
+	vc.CreatedAt = time.Now().UTC()
+	vc.UpdatedAt = time.Now().UTC()
+}
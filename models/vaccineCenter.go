@@ -0,0 +1,75 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+	validator "vaccinationDrive/validators"
+)
+
+const (
+	PincodePattern  = `^[1-9][0-9]{5}$`
+	TimeOfDayLayout = "15:04"
+)
+
+type VaccineCenter struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name" validate:"required" sql:",notnull"`
+	Address     string    `json:"address" validate:"required" sql:",notnull"`
+	District    string    `json:"district" validate:"required" sql:",notnull"`
+	Pincode     string    `json:"pincode" validate:"required" sql:",notnull"`
+	OpeningTime string    `json:"openingTime" validate:"required" sql:",notnull"`
+	ClosingTime string    `json:"closingTime" validate:"required" sql:",notnull"`
+	IsActive    bool      `json:"isActive" sql:",notnull"`
+	CreatedAt   time.Time `json:"createdAt" sql:",default:now()"`
+	UpdatedAt   time.Time `json:"updatedAt" sql:",default:now()"`
+}
+
+//ErrVaccineCenterExists is a center named like another one of the district
+var ErrVaccineCenterExists = &DomainError{
+	Code:    CodeConflict,
+	Message: "Vaccine center is already present in the district",
+	Fields:  validator.Errors{"name": errors.New("Vaccine center is already present in the district")},
+}
+
+//Validate is validation for VaccineCenter fields, the uniqueness of the name is checked
+//when saving
+func (vc VaccineCenter) Validate() (validator.Errors, error) {
+	v := validator.New("VaccineCenter")
+
+	if vc.Pincode != "" {
+		v.ValidateField("pincode", vc.Pincode, []validator.Tag{
+			{Name: "regexp", Fn: validator.Regex, Param: PincodePattern},
+		})
+	}
+
+	opening, openErr := time.Parse(TimeOfDayLayout, vc.OpeningTime)
+	if vc.OpeningTime != "" && openErr != nil {
+		v.AddError("openingTime", errors.New("Opening time should be in HH:MM format"))
+	}
+
+	closing, closeErr := time.Parse(TimeOfDayLayout, vc.ClosingTime)
+	if vc.ClosingTime != "" && closeErr != nil {
+		v.AddError("closingTime", errors.New("Closing time should be in HH:MM format"))
+	}
+
+	if openErr == nil && closeErr == nil && !closing.After(opening) {
+		v.AddError("closingTime", errors.New("Closing time should be after opening time"))
+	}
+
+	return v.Validate(vc)
+}
+
+//Normalize trims the free text fields of the center
+func (vc *VaccineCenter) Normalize() {
+	vc.Name = strings.Join(strings.Fields(vc.Name), " ")
+	vc.Address = strings.TrimSpace(vc.Address)
+	vc.District = strings.Join(strings.Fields(vc.District), " ")
+	vc.Pincode = strings.TrimSpace(vc.Pincode)
+}
+
+// BeforeInsert func
+func (vc *VaccineCenter) BeforeInsert() {
+	vc.CreatedAt = time.Now().UTC()
+	vc.UpdatedAt = time.Now().UTC()
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"vaccinationDrive/dbcon"
+	"vaccinationDrive/dbscripts"
+)
+
+//rotatePIICommand re-encrypts the ID numbers sealed with an older key with pii_key_version,
+//run once after rotating the key and before removing the old one
+func rotatePIICommand(fs *flag.FlagSet) func(args []string) error {
+	batch := fs.Int("batch", 500, "users re-encrypted per transaction")
+
+	return func(args []string) error {
+		rotated, err := dbscripts.RotateIDNumbers(dbcon.Get(), *batch)
+		if err != nil {
+			return err
+		}
+
+		log.Printf("Re-encrypted the ID numbers of %d users with the current key", rotated)
+		return nil
+	}
+}
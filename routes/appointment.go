@@ -1,7 +1,10 @@
 package routes
 
 import (
+	"context"
 	"net/http"
+	"strconv"
+	"strings"
 	app "vaccinationDrive/internals/services/appointment"
 	"vaccinationDrive/models"
 
@@ -9,25 +12,33 @@ import (
 	"github.com/justinas/alice"
 )
 
-func appointment(router *httprouter.Router, indexHandlers alice.Chain) {
-	router.POST("/bookappointment", wrapHandler(indexHandlers.ThenFunc(BookAppointment)))
-	router.PUT("/updateappointment/:id", wrapHandler(indexHandlers.ThenFunc(UpdateAppointment)))
+func appointment(router *httprouter.Router, authHandlers alice.Chain) {
+	staffHandlers := authHandlers.Append(requireRole(models.RoleAdmin, models.RoleCenterStaff))
+
+	router.POST("/bookappointment", wrapHandler("POST /bookappointment", authHandlers.ThenFunc(BookAppointment)))
+	router.PUT("/updateappointment/:id", wrapHandler("PUT /updateappointment/:id", authHandlers.ThenFunc(UpdateAppointment)))
+	router.GET("/appointments/:id", wrapHandler("GET /appointments/:id", authHandlers.ThenFunc(GetAppointment)))
+	router.DELETE("/appointments/:id", wrapHandler("DELETE /appointments/:id", authHandlers.ThenFunc(CancelAppointment)))
+	router.PUT("/appointments/:id/checkin", wrapHandler("PUT /appointments/:id/checkin", staffHandlers.ThenFunc(CheckInAppointment)))
+	router.PUT("/appointments/:id/vaccinate", wrapHandler("PUT /appointments/:id/vaccinate", staffHandlers.ThenFunc(VaccinateAppointment)))
+	router.PUT("/appointments/:id/noshow", wrapHandler("PUT /appointments/:id/noshow", staffHandlers.ThenFunc(NoShowAppointment)))
+	router.GET("/users/:id/appointments", wrapHandler("GET /users/:id/appointments", authHandlers.ThenFunc(GetBeneficiaryAppointments)))
+	router.GET("/centers/:id/appointments", wrapHandler("GET /centers/:id/appointments", staffHandlers.ThenFunc(GetCenterAppointments)))
 }
 
 func BookAppointment(w http.ResponseWriter, r *http.Request) {
-	appointmentIns := models.Appointment{}
+	appointmentIns := models.AppointmentRequest{}
 
 	rd := logAndGetContext(w, r)
 
-	if !parseJSON(w, r.Body, &appointmentIns) {
+	if !parseJSON(rd, r.Body, &appointmentIns) {
 		return
 	}
 
 	appIns := app.NewAppointmentData(rd.l, rd.dbConn)
-	err := appIns.BookAppointment(appointmentIns)
+	err := appIns.BookAppointment(r.Context(), getCaller(r), appointmentIns)
 	if err != nil {
-		rd.l.Errorf("BookAppointment - ", err.Error())
-		writeJSONMessage(err.Error(), ERR_MSG, http.StatusBadRequest, rd)
+		writeError(err, rd)
 		return
 	}
 
@@ -43,33 +54,182 @@ func BookAppointment(w http.ResponseWriter, r *http.Request) {
 
 func UpdateAppointment(w http.ResponseWriter, r *http.Request) {
 
-	// ID, isErr := GetIDFromParams(w, r, "id")
-	// if !isErr {
-	// 	return
-	// }
+	rd := logAndGetContext(w, r)
+
+	ID, isErr := GetIDFromParams(rd, "id")
+	if !isErr {
+		return
+	}
 
 	appointmentIns := models.Appointment{}
 
+	if !parseJSON(rd, r.Body, &appointmentIns) {
+		return
+	}
+
+	appIns := app.NewAppointmentData(rd.l, rd.dbConn)
+	res, err := appIns.RescheduleAppointment(r.Context(), getCaller(r), ID, appointmentIns)
+	if err != nil {
+		writeError(err, rd)
+		return
+	}
+
+	writeJSONStruct(res, http.StatusOK, rd)
+
+}
+
+func CancelAppointment(w http.ResponseWriter, r *http.Request) {
+
 	rd := logAndGetContext(w, r)
 
-	if !parseJSON(w, r.Body, &appointmentIns) {
+	ID, isErr := GetIDFromParams(rd, "id")
+	if !isErr {
+		return
+	}
+
+	cancellation := models.AppointmentCancellation{}
+
+	if !parseJSON(rd, r.Body, &cancellation) {
+		return
+	}
+
+	if errs, err := cancellation.Validate(); err != nil {
+		renderValidationError(rd, http.StatusBadRequest, errs)
 		return
 	}
 
 	appIns := app.NewAppointmentData(rd.l, rd.dbConn)
-	err := appIns.BookAppointment(appointmentIns)
+	res, err := appIns.CancelAppointment(r.Context(), getCaller(r), ID, cancellation)
 	if err != nil {
-		rd.l.Errorf("BookAppointment - ", err.Error())
-		writeJSONMessage(err.Error(), ERR_MSG, http.StatusBadRequest, rd)
+		writeError(err, rd)
 		return
 	}
 
-	res := struct {
-		Message string `json:"message"`
-	}{
-		"Your appointment has been Booked successfully..",
+	writeJSONStruct(res, http.StatusOK, rd)
+
+}
+
+func CheckInAppointment(w http.ResponseWriter, r *http.Request) {
+	updateAppointmentStatus(w, r, "CheckInAppointment", (*app.AppointmentData).CheckInAppointment)
+}
+
+func VaccinateAppointment(w http.ResponseWriter, r *http.Request) {
+	updateAppointmentStatus(w, r, "VaccinateAppointment", (*app.AppointmentData).VaccinateAppointment)
+}
+
+func NoShowAppointment(w http.ResponseWriter, r *http.Request) {
+	updateAppointmentStatus(w, r, "NoShowAppointment", (*app.AppointmentData).NoShowAppointment)
+}
+
+//updateAppointmentStatus runs a status transition of the appointment in the path
+func updateAppointmentStatus(w http.ResponseWriter, r *http.Request, name string, transition func(*app.AppointmentData, context.Context, models.Caller, int64) (*models.Appointment, error)) {
+
+	rd := logAndGetContext(w, r)
+
+	ID, isErr := GetIDFromParams(rd, "id")
+	if !isErr {
+		return
+	}
+
+	appIns := app.NewAppointmentData(rd.l, rd.dbConn)
+	res, err := transition(appIns, r.Context(), getCaller(r), ID)
+	if err != nil {
+		writeError(err, rd)
+		return
+	}
+
+	writeJSONStruct(res, http.StatusOK, rd)
+}
+
+func GetAppointment(w http.ResponseWriter, r *http.Request) {
+
+	rd := logAndGetContext(w, r)
+
+	ID, isErr := GetIDFromParams(rd, "id")
+	if !isErr {
+		return
+	}
+
+	appIns := app.NewAppointmentData(rd.l, rd.dbConn)
+	res, err := appIns.GetAppointment(r.Context(), getCaller(r), ID)
+	if err != nil {
+		writeError(err, rd)
+		return
+	}
+
+	writeJSONStruct(res, http.StatusOK, rd)
+}
+
+func GetBeneficiaryAppointments(w http.ResponseWriter, r *http.Request) {
+
+	rd := logAndGetContext(w, r)
+
+	ID, isErr := GetIDFromParams(rd, "id")
+	if !isErr {
+		return
+	}
+
+	filter, ok := getAppointmentFilter(r, rd)
+	if !ok {
+		return
+	}
+
+	appIns := app.NewAppointmentData(rd.l, rd.dbConn)
+	res, err := appIns.GetBeneficiaryAppointments(r.Context(), getCaller(r), ID, filter)
+	if err != nil {
+		writeError(err, rd)
+		return
+	}
+
+	writeJSONStruct(res, http.StatusOK, rd)
+}
+
+func GetCenterAppointments(w http.ResponseWriter, r *http.Request) {
+
+	rd := logAndGetContext(w, r)
+
+	ID, isErr := GetIDFromParams(rd, "id")
+	if !isErr {
+		return
+	}
+
+	filter, ok := getAppointmentFilter(r, rd)
+	if !ok {
+		return
+	}
+	filter.Date = r.URL.Query().Get("date")
+
+	appIns := app.NewAppointmentData(rd.l, rd.dbConn)
+	res, err := appIns.GetCenterAppointments(r.Context(), getCaller(r), ID, filter)
+	if err != nil {
+		writeError(err, rd)
+		return
 	}
 
 	writeJSONStruct(res, http.StatusOK, rd)
+}
+
+//getAppointmentFilter reads the listing params and the dose & status filters from the query string
+func getAppointmentFilter(r *http.Request, rd *RequestData) (models.AppointmentFilter, bool) {
+	lp, err := getListParams(r)
+	if err != nil {
+		writeError(err, rd)
+		return models.AppointmentFilter{}, false
+	}
+
+	filter := models.AppointmentFilter{
+		Status:     strings.ToUpper(r.URL.Query().Get("status")),
+		ListParams: lp,
+	}
+
+	if dose := r.URL.Query().Get("dose"); dose != "" {
+		d, err := strconv.Atoi(dose)
+		if err != nil || d < 1 {
+			writeError(models.InvalidParam("dose", "dose should be a positive number"), rd)
+			return filter, false
+		}
+		filter.Dose = d
+	}
 
+	return filter, true
 }
@@ -0,0 +1,182 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"vaccinationDrive/internals/logger"
+	"vaccinationDrive/internals/services/auth"
+	"vaccinationDrive/internals/token"
+	"vaccinationDrive/models"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/justinas/alice"
+)
+
+func authentication(router *httprouter.Router, indexHandlers alice.Chain, authHandlers alice.Chain) {
+	router.POST("/auth/otp", wrapHandler("POST /auth/otp", indexHandlers.ThenFunc(RequestOTP)))
+	router.POST("/auth/otp/verify", wrapHandler("POST /auth/otp/verify", indexHandlers.ThenFunc(VerifyOTP)))
+	router.POST("/auth/refresh", wrapHandler("POST /auth/refresh", indexHandlers.ThenFunc(RefreshTokens)))
+	router.GET("/.well-known/jwks.json", wrapHandler("GET /.well-known/jwks.json", indexHandlers.ThenFunc(GetJWKS)))
+	router.POST("/auth/logout", wrapHandler("POST /auth/logout", authHandlers.ThenFunc(Logout)))
+}
+
+// Reject requests without a valid access token and put the caller in context
+func authHandler(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		rd := logAndGetContext(w, r)
+
+		token := bearerToken(r)
+		if token == "" {
+			writeError(models.Unauthorized("Authorization token is required"), rd)
+			return
+		}
+
+		authIns := auth.NewAuthData(rd.l, rd.dbConn)
+		caller, err := authIns.Authenticate(r.Context(), token)
+		if err != nil {
+			writeError(err, rd)
+			return
+		}
+
+		if info := logger.RequestFrom(r.Context()); info != nil {
+			info.UserID = caller.AccountID
+		}
+
+		ctx := context.WithValue(r.Context(), "caller", *caller)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+//requireRole rejects callers without any of the roles, it runs after authHandler
+func requireRole(roles ...string) alice.Constructor {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if caller := getCaller(r); !caller.HasRole(roles...) {
+				rd := logAndGetContext(w, r)
+				//the roles of the route are logged along with the denial
+				rd.l = rd.l.With("roles", roles)
+				writeForbidden(rd)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+//bearerToken reads the token of the Authorization header
+func bearerToken(r *http.Request) string {
+	h := r.Header.Get("Authorization")
+	if len(h) < 7 || !strings.EqualFold(h[:7], "Bearer ") {
+		return ""
+	}
+	return strings.TrimSpace(h[7:])
+}
+
+//getCaller returns the caller put in context by authHandler
+func getCaller(r *http.Request) models.Caller {
+	caller, _ := r.Context().Value("caller").(models.Caller)
+	return caller
+}
+
+func RequestOTP(w http.ResponseWriter, r *http.Request) {
+	rd := logAndGetContext(w, r)
+
+	req := models.OTPRequest{}
+
+	if !parseJSON(rd, r.Body, &req) {
+		return
+	}
+
+	if errs, err := req.Validate(); err != nil {
+		renderValidationError(rd, http.StatusBadRequest, errs)
+		return
+	}
+
+	authIns := auth.NewAuthData(rd.l, rd.dbConn)
+	if err := authIns.RequestOTP(r.Context(), req); err != nil {
+		writeError(err, rd)
+		return
+	}
+
+	writeJSONMessage("OTP sent successfully", MSG, http.StatusOK, rd)
+}
+
+func VerifyOTP(w http.ResponseWriter, r *http.Request) {
+	rd := logAndGetContext(w, r)
+
+	req := models.OTPVerification{}
+
+	if !parseJSON(rd, r.Body, &req) {
+		return
+	}
+
+	if errs, err := req.Validate(); err != nil {
+		renderValidationError(rd, http.StatusBadRequest, errs)
+		return
+	}
+
+	authIns := auth.NewAuthData(rd.l, rd.dbConn)
+	res, err := authIns.VerifyOTP(r.Context(), req)
+	if err != nil {
+		writeError(err, rd)
+		return
+	}
+
+	writeJSONStruct(res, http.StatusOK, rd)
+}
+
+func Logout(w http.ResponseWriter, r *http.Request) {
+	rd := logAndGetContext(w, r)
+
+	authIns := auth.NewAuthData(rd.l, rd.dbConn)
+	if err := authIns.Logout(r.Context(), getCaller(r)); err != nil {
+		writeError(err, rd)
+		return
+	}
+
+	writeJSONMessage("Logged out successfully", MSG, http.StatusOK, rd)
+}
+
+func RefreshTokens(w http.ResponseWriter, r *http.Request) {
+	rd := logAndGetContext(w, r)
+
+	req := models.RefreshRequest{}
+
+	if !parseJSON(rd, r.Body, &req) {
+		return
+	}
+
+	if errs, err := req.Validate(); err != nil {
+		renderValidationError(rd, http.StatusBadRequest, errs)
+		return
+	}
+
+	authIns := auth.NewAuthData(rd.l, rd.dbConn)
+	res, err := authIns.RefreshTokens(r.Context(), req)
+	if err != nil {
+		writeError(err, rd)
+		return
+	}
+
+	writeJSONStruct(res, http.StatusOK, rd)
+}
+
+//GetJWKS publishes the public keys the access tokens can be verified with
+func GetJWKS(w http.ResponseWriter, r *http.Request) {
+	rd := logAndGetContext(w, r)
+
+	ks, err := token.Get()
+	if err != nil {
+		writeError(err, rd)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	writeJSONStruct(ks.JWKS(), http.StatusOK, rd)
+}
@@ -0,0 +1,68 @@
+package routes
+
+import (
+	"net/http"
+	"vaccinationDrive/internals/services/capacity"
+	"vaccinationDrive/internals/settings"
+	"vaccinationDrive/models"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/justinas/alice"
+)
+
+func capacities(router *httprouter.Router, indexHandlers alice.Chain, authHandlers alice.Chain) {
+	adminHandlers := authHandlers.Append(requireRole(models.RoleAdmin))
+
+	router.PUT("/centers/:id/capacity", wrapHandler("PUT /centers/:id/capacity", adminHandlers.ThenFunc(PublishCapacity)))
+	router.GET("/centers/:id/capacity", wrapHandler("GET /centers/:id/capacity", indexHandlers.ThenFunc(GetCapacities)))
+}
+
+func PublishCapacity(w http.ResponseWriter, r *http.Request) {
+	rd := logAndGetContext(w, r)
+
+	ID, isErr := GetIDFromParams(rd, "id")
+	if !isErr {
+		return
+	}
+
+	capacityIns := models.CenterCapacity{}
+
+	if !parseJSON(rd, r.Body, &capacityIns) {
+		return
+	}
+
+	capacityIns.VaccineCenterID = ID
+	s := settings.Get()
+	capacityIns.ApplyDefaults(s.DefaultDailyQuota, s.DefaultSlotQuotas)
+	if errs, err := capacityIns.Validate(); err != nil {
+		renderValidationError(rd, http.StatusBadRequest, errs)
+		return
+	}
+
+	capIns := capacity.NewCapacityData(rd.l, rd.dbConn)
+	res, err := capIns.PublishCapacity(r.Context(), capacityIns)
+	if err != nil {
+		writeError(err, rd)
+		return
+	}
+
+	writeJSONStruct(res, http.StatusOK, rd)
+}
+
+func GetCapacities(w http.ResponseWriter, r *http.Request) {
+	rd := logAndGetContext(w, r)
+
+	ID, isErr := GetIDFromParams(rd, "id")
+	if !isErr {
+		return
+	}
+
+	capIns := capacity.NewCapacityData(rd.l, rd.dbConn)
+	res, err := capIns.GetCapacities(r.Context(), ID, r.URL.Query().Get("date"))
+	if err != nil {
+		writeError(err, rd)
+		return
+	}
+
+	writeJSONStruct(res, http.StatusOK, rd)
+}
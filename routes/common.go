@@ -7,12 +7,14 @@ import (
 	lg "log"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 	"vaccinationDrive/dbcon"
+	"vaccinationDrive/internals/logger"
+	"vaccinationDrive/internals/settings"
 	"vaccinationDrive/models"
 	validator "vaccinationDrive/validators"
 
-	"github.com/FenixAra/go-util/log"
 	"github.com/go-pg/pg"
 	"github.com/julienschmidt/httprouter"
 )
@@ -41,7 +43,7 @@ type Res400Struct struct {
 }
 
 type RequestData struct {
-	l      *log.Logger
+	l      *logger.Logger
 	dbConn *pg.DB
 	Start  time.Time
 	w      http.ResponseWriter
@@ -64,20 +66,14 @@ func (t *TemplateData) SetConstants() {
 func logAndGetContext(w http.ResponseWriter, r *http.Request) *RequestData {
 	w.Header().Add("X-Content-Type-Options", "nosniff")
 	w.Header().Add("X-Frame-Options", "DENY")
-	//Set config according to the use case..
-	cfg := log.NewConfig("")
-	//cfg.SetRemoteConfig(conf.LOG_REMOTE_URL, "", "")
-	cfg.SetLevelStr("")
-	cfg.SetFilePathSizeStr("")
-	cfg.SetReference(r.Header.Get("ReferenceID"))
-	l := log.New(cfg)
+	//The lines carry the request ID, route and caller set by wrapHandler and authHandler
+	l := logger.ForRequest(settings.Get().LogLevel, logger.RequestFrom(r.Context()))
 	db := dbcon.Get()
 	//dbConn := new(db.DBConn)
 	//dbConn.Init(l)
 	//pgdbConn := new(pgsqldb.Conn)
 	//pgdbConn.Init(l)
 	start := time.Now()
-	//l.LogAPIInfo(r, 0, 0)
 	return &RequestData{
 		l:      l,
 		dbConn: db,
@@ -109,6 +105,10 @@ func jsonifyMessage(msg string, msgType string, httpCode int) ([]byte, int) {
 }
 
 func writeJSONMessage(msg string, msgType string, httpCode int, rd *RequestData) {
+	if msgType == ERR_MSG {
+		writeProblem(models.NewProblem(httpCode, msg), rd)
+		return
+	}
 	d, code := jsonifyMessage(msg, msgType, httpCode)
 	writeJSONResponse(d, code, rd)
 }
@@ -123,12 +123,6 @@ func writeJSONStruct(v interface{}, code int, rd *RequestData) {
 }
 
 func writeJSONResponse(d []byte, code int, rd *RequestData) {
-	rd.l.LogAPIInfo(rd.r, time.Since(rd.Start).Seconds(), code)
-	if code == http.StatusInternalServerError {
-		rd.l.Info(rd.r.URL.String(), "Status Code:", code, ", Response time:", time.Since(rd.Start), " Response:", string(d))
-	} else {
-		rd.l.Info(rd.r.URL.String(), "Status Code:", code, ", Response time:", time.Since(rd.Start))
-	}
 	rd.w.Header().Set("Access-Control-Allow-Origin", "*")
 	rd.w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	rd.w.WriteHeader(code)
@@ -136,15 +130,17 @@ func writeJSONResponse(d []byte, code int, rd *RequestData) {
 }
 
 func writeJSONMessageWithData(msg string, msgType string, httpCode int, rd *RequestData, functionName string, requestData string) {
+	if msgType == ERR_MSG {
+		writeProblem(models.NewProblem(httpCode, msg), rd)
+		return
+	}
 	d, code := jsonifyMessage(msg, msgType, httpCode)
 	writeJSONResponseWithData(d, code, rd, functionName, requestData)
 }
 
 func writeJSONResponseWithData(d []byte, code int, rd *RequestData, functionName string, requestData string) {
-	rd.l.LogAPIInfo(rd.r, time.Since(rd.Start).Seconds(), code)
-
-	rd.l.Info("Service name : ", functionName, ", Request Data : ", requestData,
-		", Response data : ", string(d), ", Status Code : ", code, ", Response time : ", time.Since(rd.Start))
+	rd.l.With("service", functionName).With("request", requestData).With("response", string(d)).
+		Debugf("%s responded %d", functionName, code)
 
 	rd.w.Header().Set("Access-Control-Allow-Origin", "*")
 	rd.w.Header().Set("Content-Type", "application/json; charset=utf-8")
@@ -175,50 +171,111 @@ func renderJSON(w http.ResponseWriter, status int, res interface{}) {
 }
 
 //renderValidationError to render error msg validation
-func renderValidationError(w http.ResponseWriter, status int, errs validator.Errors) {
+func renderValidationError(rd *RequestData, status int, errs validator.Errors) {
+	p := models.ProblemFrom(models.ValidationFailed(errs))
+	p.Status = status
 
-	res := struct {
-		Message string           `json:"message"`
-		Errors  validator.Errors `json:"errors"`
-	}{"Validation Error(s)", errs}
-	renderJSON(w, status, res)
+	rd.l.With("code", p.Code).With("errors", errs).Debugf("%s", p.Detail)
+	renderProblem(p, rd)
 }
 
-func parseJSON(w http.ResponseWriter, body io.ReadCloser, model interface{}) bool {
+func parseJSON(rd *RequestData, body io.ReadCloser, model interface{}) bool {
 	defer body.Close()
 
 	b, _ := ioutil.ReadAll(body)
 	err := json.Unmarshal(b, model)
 
 	if err != nil {
-		e := &models.ErrorData{}
-		e.Message = "Error in parsing json"
-		e.Err = err
-		renderERRORWithIn(w, e)
+		writeProblem(models.NewProblem(http.StatusBadRequest, "Error in parsing json"), rd)
 		return false
 	}
 
 	return true
 }
 
-func renderERRORWithIn(w http.ResponseWriter, err *models.ErrorData) {
-	err.Set()
-	renderJSON(w, err.Code, err)
+//writeProblem renders the RFC 7807 body every error response is rendered with,
+//its detail is logged at DEBUG since the access log already has the status
+func writeProblem(p models.Problem, rd *RequestData) {
+	rd.l.With("code", p.Code).Debugf("%s", p.Detail)
+	renderProblem(p, rd)
+}
+
+//renderProblem writes the problem without logging it, for callers which already did
+func renderProblem(p models.Problem, rd *RequestData) {
+	p.Instance = rd.r.URL.Path
+	d, _ := json.Marshal(p)
+
+	rd.w.Header().Set("Access-Control-Allow-Origin", "*")
+	rd.w.Header().Set("Content-Type", "application/problem+json; charset=utf-8")
+	rd.w.WriteHeader(p.Status)
+	rd.w.Write(d)
+}
+
+//writeError renders an error returned by a service or a dao. Domain errors keep
+//their code and status, errors the client can't act on are rendered as internal
+//errors. This is the one place the error of a request is logged, at ERROR when
+//it is the server's fault and at DEBUG otherwise.
+func writeError(err error, rd *RequestData) {
+	p := models.ProblemFrom(err)
+
+	l := rd.l.With("code", p.Code).With("error", err)
+	if p.Status >= http.StatusInternalServerError {
+		l.Errorf("%s", p.Detail)
+	} else {
+		l.Debugf("%s", p.Detail)
+	}
+	renderProblem(p, rd)
+}
+
+//writeForbidden renders the denial of every route the caller is not allowed to use
+func writeForbidden(rd *RequestData) {
+	writeError(models.ErrForbidden, rd)
+}
+
+//getListParams reads page, limit and sort from the query string
+func getListParams(r *http.Request) (models.ListParams, error) {
+	query := r.URL.Query()
+	lp := models.ListParams{
+		Page:  1,
+		Limit: models.DefaultPageLimit,
+		Sort:  models.SortAsc,
+	}
+
+	if page := query.Get("page"); page != "" {
+		p, err := strconv.Atoi(page)
+		if err != nil || p < 1 {
+			return lp, models.InvalidParam("page", "page should be a positive number")
+		}
+		lp.Page = p
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil || l < 1 || l > models.MaxPageLimit {
+			return lp, models.InvalidParam("limit", "limit should be between 1 and %d", models.MaxPageLimit)
+		}
+		lp.Limit = l
+	}
+
+	if sort := strings.ToLower(query.Get("sort")); sort != "" {
+		if sort != models.SortAsc && sort != models.SortDesc {
+			return lp, models.InvalidParam("sort", "sort should be either asc or desc")
+		}
+		lp.Sort = sort
+	}
+
+	return lp, nil
 }
 
-func GetIDFromParams(w http.ResponseWriter, r *http.Request, key string) (int64, bool) {
-	params, _ := r.Context().Value("params").(httprouter.Params)
+func GetIDFromParams(rd *RequestData, key string) (int64, bool) {
+	params, _ := rd.r.Context().Value("params").(httprouter.Params)
 	idStr := params.ByName(key)
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	isErr := true
 
 	if err != nil {
 		isErr = false
-		e := &models.ErrorData{}
-		e.Message = "Invalid ID"
-		e.Code = http.StatusBadRequest
-		e.Err = err
-		renderERRORWithIn(w, e)
+		writeProblem(models.NewProblem(http.StatusBadRequest, "Invalid ID"), rd)
 	}
 
 	return id, isErr
@@ -0,0 +1,126 @@
+package routes
+
+import (
+	"net/http"
+	"vaccinationDrive/internals/services/eligibility"
+	"vaccinationDrive/models"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/justinas/alice"
+)
+
+func eligibilityPolicies(router *httprouter.Router, indexHandlers alice.Chain, authHandlers alice.Chain) {
+	adminHandlers := authHandlers.Append(requireRole(models.RoleAdmin))
+
+	router.POST("/eligibility-policies", wrapHandler("POST /eligibility-policies", adminHandlers.ThenFunc(CreateEligibilityPolicy)))
+	router.GET("/eligibility-policies", wrapHandler("GET /eligibility-policies", indexHandlers.ThenFunc(GetEligibilityPolicies)))
+	router.GET("/eligibility-policies/:id", wrapHandler("GET /eligibility-policies/:id", indexHandlers.ThenFunc(GetEligibilityPolicy)))
+	router.PUT("/eligibility-policies/:id", wrapHandler("PUT /eligibility-policies/:id", adminHandlers.ThenFunc(UpdateEligibilityPolicy)))
+	router.PUT("/eligibility-policies/:id/deactivate", wrapHandler("PUT /eligibility-policies/:id/deactivate", adminHandlers.ThenFunc(DeactivateEligibilityPolicy)))
+}
+
+func CreateEligibilityPolicy(w http.ResponseWriter, r *http.Request) {
+	rd := logAndGetContext(w, r)
+
+	policy := models.EligibilityPolicy{}
+
+	if !parseJSON(rd, r.Body, &policy) {
+		return
+	}
+
+	policy.Normalize()
+	if errs, err := policy.Validate(); err != nil {
+		renderValidationError(rd, http.StatusBadRequest, errs)
+		return
+	}
+
+	policyIns := eligibility.NewEligibilityData(rd.l, rd.dbConn)
+	res, err := policyIns.CreatePolicy(r.Context(), policy)
+	if err != nil {
+		writeError(err, rd)
+		return
+	}
+
+	writeJSONStruct(res, http.StatusCreated, rd)
+}
+
+func GetEligibilityPolicies(w http.ResponseWriter, r *http.Request) {
+	rd := logAndGetContext(w, r)
+
+	activeOnly := r.URL.Query().Get("active") != "false"
+
+	policyIns := eligibility.NewEligibilityData(rd.l, rd.dbConn)
+	res, err := policyIns.GetPolicies(r.Context(), activeOnly)
+	if err != nil {
+		writeError(err, rd)
+		return
+	}
+
+	writeJSONStruct(res, http.StatusOK, rd)
+}
+
+func GetEligibilityPolicy(w http.ResponseWriter, r *http.Request) {
+	rd := logAndGetContext(w, r)
+
+	ID, isErr := GetIDFromParams(rd, "id")
+	if !isErr {
+		return
+	}
+
+	policyIns := eligibility.NewEligibilityData(rd.l, rd.dbConn)
+	res, err := policyIns.GetPolicy(r.Context(), ID)
+	if err != nil {
+		writeError(err, rd)
+		return
+	}
+
+	writeJSONStruct(res, http.StatusOK, rd)
+}
+
+func UpdateEligibilityPolicy(w http.ResponseWriter, r *http.Request) {
+	rd := logAndGetContext(w, r)
+
+	ID, isErr := GetIDFromParams(rd, "id")
+	if !isErr {
+		return
+	}
+
+	policy := models.EligibilityPolicy{}
+
+	if !parseJSON(rd, r.Body, &policy) {
+		return
+	}
+
+	policy.ID = ID
+	policy.Normalize()
+	if errs, err := policy.Validate(); err != nil {
+		renderValidationError(rd, http.StatusBadRequest, errs)
+		return
+	}
+
+	policyIns := eligibility.NewEligibilityData(rd.l, rd.dbConn)
+	res, err := policyIns.UpdatePolicy(r.Context(), policy)
+	if err != nil {
+		writeError(err, rd)
+		return
+	}
+
+	writeJSONStruct(res, http.StatusOK, rd)
+}
+
+func DeactivateEligibilityPolicy(w http.ResponseWriter, r *http.Request) {
+	rd := logAndGetContext(w, r)
+
+	ID, isErr := GetIDFromParams(rd, "id")
+	if !isErr {
+		return
+	}
+
+	policyIns := eligibility.NewEligibilityData(rd.l, rd.dbConn)
+	if err := policyIns.DeactivatePolicy(r.Context(), ID); err != nil {
+		writeError(err, rd)
+		return
+	}
+
+	writeJSONMessage("Eligibility policy deactivated successfully", MSG, http.StatusOK, rd)
+}
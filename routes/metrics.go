@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"expvar"
+	"vaccinationDrive/models"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/justinas/alice"
+)
+
+//metrics publishes the expvar counters, Eg. the transaction retries, to admins
+func metrics(router *httprouter.Router, authHandlers alice.Chain) {
+	adminHandlers := authHandlers.Append(requireRole(models.RoleAdmin))
+
+	router.GET("/debug/vars", wrapHandler("GET /debug/vars", adminHandlers.Then(expvar.Handler())))
+}
@@ -1,61 +1,144 @@
 package routes
 
 import (
-	"math"
 	"net/http"
-	"time"
 	"vaccinationDrive/internals/services/userRegistration"
 	"vaccinationDrive/models"
-	"vaccinationDrive/utils"
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/justinas/alice"
 )
 
-func registration(router *httprouter.Router, indexHandlers alice.Chain) {
-	router.POST("/user", wrapHandler(indexHandlers.ThenFunc(RegisterUser)))
+func registration(router *httprouter.Router, authHandlers alice.Chain) {
+	router.POST("/user", wrapHandler("POST /user", authHandlers.ThenFunc(RegisterUser)))
+	router.GET("/accounts/:id/members", wrapHandler("GET /accounts/:id/members", authHandlers.ThenFunc(GetAccountMembers)))
+	router.POST("/accounts/:id/members", wrapHandler("POST /accounts/:id/members", authHandlers.ThenFunc(AddAccountMember)))
+	router.DELETE("/accounts/:id/members/:memberId", wrapHandler("DELETE /accounts/:id/members/:memberId", authHandlers.ThenFunc(RemoveAccountMember)))
 }
 
+//RegisterUser registers the beneficiary under the account of the caller
 func RegisterUser(w http.ResponseWriter, r *http.Request) {
 	rd := logAndGetContext(w, r)
 
 	user := models.User{}
 
-	if !parseJSON(w, r.Body, &user) {
+	if !parseJSON(rd, r.Body, &user) {
 		return
 	}
 
-	if errs, err := user.Validate(); err != nil {
-		rd.l.Error("Errors : ", errs)
-		renderValidationError(w, http.StatusBadRequest, errs)
+	addMember(getCaller(r).AccountID, user, rd)
+}
+
+func AddAccountMember(w http.ResponseWriter, r *http.Request) {
+	rd := logAndGetContext(w, r)
+
+	ID, isErr := GetIDFromParams(rd, "id")
+	if !isErr {
 		return
 	}
 
-	today := time.Now()
-	dateofbirth, _ := utils.StringddMMyyyyToDate(user.DOB)
+	if !getCaller(r).CanAccessAccount(ID) {
+		writeForbidden(rd)
+		return
+	}
 
-	user.Age = math.Floor(today.Sub(dateofbirth).Hours() / 24 / 365)
+	user := models.User{}
 
-	if user.Age < 45 {
-		rd.l.Errorf("user age should be less than 45")
-		writeJSONMessage("user age should be less than 45", ERR_MSG, http.StatusBadRequest, rd)
+	if !parseJSON(rd, r.Body, &user) {
 		return
 	}
 
+	addMember(ID, user, rd)
+}
+
+//addMember registers the beneficiary under the phone number of the account
+func addMember(accountID int64, user models.User, rd *RequestData) {
 	userIns := userRegistration.NewUserData(rd.l, rd.dbConn)
-	err := userIns.RegisterUser(user)
+	account, err := userIns.GetAccount(rd.r.Context(), accountID)
 	if err != nil {
-		rd.l.Errorf("error in insert user", err.Error())
-		writeJSONMessage(err.Error(), ERR_MSG, http.StatusBadRequest, rd)
+		writeError(err, rd)
+		return
+	}
+
+	user.PhoneNumber = account.PhoneNumber
+	registerUser(user, rd)
+}
+
+//registerUser validates and registers the beneficiary under the account of its phone number
+func registerUser(user models.User, rd *RequestData) {
+	user.Normalize()
+	if errs, err := user.Validate(); err != nil {
+		renderValidationError(rd, http.StatusBadRequest, errs)
+		return
+	}
+
+	userIns := userRegistration.NewUserData(rd.l, rd.dbConn)
+	saved, err := userIns.RegisterUser(rd.r.Context(), user)
+	if err != nil {
+		writeError(err, rd)
 		return
 	}
 
 	res := struct {
-		Message string `json:"message"`
+		Message   string `json:"message"`
+		ID        int64  `json:"id"`
+		AccountID int64  `json:"accountId"`
 	}{
 		"User Registered Successfully...",
+		saved.ID,
+		saved.AccountID,
+	}
+
+	writeJSONStruct(res, http.StatusOK, rd)
+
+}
+
+func GetAccountMembers(w http.ResponseWriter, r *http.Request) {
+	rd := logAndGetContext(w, r)
+
+	ID, isErr := GetIDFromParams(rd, "id")
+	if !isErr {
+		return
+	}
+
+	if !getCaller(r).CanAccessAccount(ID) {
+		writeForbidden(rd)
+		return
+	}
+
+	userIns := userRegistration.NewUserData(rd.l, rd.dbConn)
+	res, err := userIns.GetMembers(r.Context(), ID)
+	if err != nil {
+		writeError(err, rd)
+		return
 	}
 
 	writeJSONStruct(res, http.StatusOK, rd)
+}
+
+func RemoveAccountMember(w http.ResponseWriter, r *http.Request) {
+	rd := logAndGetContext(w, r)
+
+	ID, isErr := GetIDFromParams(rd, "id")
+	if !isErr {
+		return
+	}
+
+	memberID, isErr := GetIDFromParams(rd, "memberId")
+	if !isErr {
+		return
+	}
+
+	if !getCaller(r).CanAccessAccount(ID) {
+		writeForbidden(rd)
+		return
+	}
+
+	userIns := userRegistration.NewUserData(rd.l, rd.dbConn)
+	if err := userIns.RemoveMember(r.Context(), ID, memberID); err != nil {
+		writeError(err, rd)
+		return
+	}
 
+	writeJSONMessage("Member removed successfully", MSG, http.StatusOK, rd)
 }
@@ -0,0 +1,145 @@
+package routes
+
+import (
+	"net/http"
+	"strings"
+	"vaccinationDrive/internals/services/role"
+	"vaccinationDrive/models"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/justinas/alice"
+)
+
+func roles(router *httprouter.Router, authHandlers alice.Chain) {
+	adminHandlers := authHandlers.Append(requireRole(models.RoleAdmin))
+
+	router.GET("/accounts/:id/roles", wrapHandler("GET /accounts/:id/roles", adminHandlers.ThenFunc(GetAccountRoles)))
+	router.PUT("/accounts/:id/roles/:role", wrapHandler("PUT /accounts/:id/roles/:role", adminHandlers.ThenFunc(GrantAccountRole)))
+	router.DELETE("/accounts/:id/roles/:role", wrapHandler("DELETE /accounts/:id/roles/:role", adminHandlers.ThenFunc(RevokeAccountRole)))
+	router.GET("/centers/:id/staff", wrapHandler("GET /centers/:id/staff", adminHandlers.ThenFunc(GetCenterStaff)))
+	router.POST("/centers/:id/staff", wrapHandler("POST /centers/:id/staff", adminHandlers.ThenFunc(AssignCenterStaff)))
+	router.DELETE("/centers/:id/staff/:accountId", wrapHandler("DELETE /centers/:id/staff/:accountId", adminHandlers.ThenFunc(UnassignCenterStaff)))
+}
+
+func GetAccountRoles(w http.ResponseWriter, r *http.Request) {
+	rd := logAndGetContext(w, r)
+
+	ID, isErr := GetIDFromParams(rd, "id")
+	if !isErr {
+		return
+	}
+
+	roleIns := role.NewRoleData(rd.l, rd.dbConn)
+	res, err := roleIns.GetAccountAccess(r.Context(), ID)
+	if err != nil {
+		writeError(err, rd)
+		return
+	}
+
+	writeJSONStruct(res, http.StatusOK, rd)
+}
+
+func GrantAccountRole(w http.ResponseWriter, r *http.Request) {
+	rd := logAndGetContext(w, r)
+
+	ID, isErr := GetIDFromParams(rd, "id")
+	if !isErr {
+		return
+	}
+
+	roleIns := role.NewRoleData(rd.l, rd.dbConn)
+	res, err := roleIns.GrantRole(r.Context(), ID, getRoleFromParams(r))
+	if err != nil {
+		writeError(err, rd)
+		return
+	}
+
+	writeJSONStruct(res, http.StatusOK, rd)
+}
+
+func RevokeAccountRole(w http.ResponseWriter, r *http.Request) {
+	rd := logAndGetContext(w, r)
+
+	ID, isErr := GetIDFromParams(rd, "id")
+	if !isErr {
+		return
+	}
+
+	roleIns := role.NewRoleData(rd.l, rd.dbConn)
+	res, err := roleIns.RevokeRole(r.Context(), ID, getRoleFromParams(r))
+	if err != nil {
+		writeError(err, rd)
+		return
+	}
+
+	writeJSONStruct(res, http.StatusOK, rd)
+}
+
+func GetCenterStaff(w http.ResponseWriter, r *http.Request) {
+	rd := logAndGetContext(w, r)
+
+	ID, isErr := GetIDFromParams(rd, "id")
+	if !isErr {
+		return
+	}
+
+	roleIns := role.NewRoleData(rd.l, rd.dbConn)
+	res, err := roleIns.GetCenterStaff(r.Context(), ID)
+	if err != nil {
+		writeError(err, rd)
+		return
+	}
+
+	writeJSONStruct(res, http.StatusOK, rd)
+}
+
+func AssignCenterStaff(w http.ResponseWriter, r *http.Request) {
+	rd := logAndGetContext(w, r)
+
+	ID, isErr := GetIDFromParams(rd, "id")
+	if !isErr {
+		return
+	}
+
+	req := models.StaffAssignmentRequest{}
+
+	if !parseJSON(rd, r.Body, &req) {
+		return
+	}
+
+	roleIns := role.NewRoleData(rd.l, rd.dbConn)
+	res, err := roleIns.AssignStaff(r.Context(), ID, req.AccountID)
+	if err != nil {
+		writeError(err, rd)
+		return
+	}
+
+	writeJSONStruct(res, http.StatusOK, rd)
+}
+
+func UnassignCenterStaff(w http.ResponseWriter, r *http.Request) {
+	rd := logAndGetContext(w, r)
+
+	ID, isErr := GetIDFromParams(rd, "id")
+	if !isErr {
+		return
+	}
+
+	accountID, isErr := GetIDFromParams(rd, "accountId")
+	if !isErr {
+		return
+	}
+
+	roleIns := role.NewRoleData(rd.l, rd.dbConn)
+	if err := roleIns.UnassignStaff(r.Context(), ID, accountID); err != nil {
+		writeError(err, rd)
+		return
+	}
+
+	writeJSONMessage("Staff unassigned successfully", MSG, http.StatusOK, rd)
+}
+
+func getRoleFromParams(r *http.Request) string {
+	params, _ := r.Context().Value("params").(httprouter.Params)
+	return strings.ToUpper(params.ByName("role"))
+}
@@ -2,9 +2,13 @@ package routes
 
 import (
 	"context"
-	"log"
+	"fmt"
 	"net/http"
 	"runtime/debug"
+	"time"
+	"vaccinationDrive/internals/logger"
+	"vaccinationDrive/internals/settings"
+	"vaccinationDrive/models"
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/justinas/alice"
@@ -15,9 +19,9 @@ func recoverHandler(next http.Handler) http.Handler {
 	fn := func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
 			if err := recover(); err != nil {
-				log.Printf("panic: %+v", err)
-				log.Print(string(debug.Stack()))
-				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				rd := logAndGetContext(w, r)
+				rd.l.With("panic", fmt.Sprint(err)).With("stack", string(debug.Stack())).Error("Recovered from a panic")
+				writeProblem(models.NewProblem(http.StatusInternalServerError, "Something went wrong, please try again"), rd)
 			}
 		}()
 
@@ -27,12 +31,59 @@ func recoverHandler(next http.Handler) http.Handler {
 	return http.HandlerFunc(fn)
 }
 
-// Put params in context for sharing them between handlers
-func wrapHandler(next http.Handler) httprouter.Handle {
+// Put params in context for sharing them between handlers, the context is canceled
+// after the query timeout of the route so the db work of slow requests is aborted.
+// Every request gets an ID, taken from the X-Request-ID header (or the older ReferenceID)
+// when the client sent a valid one, which is echoed back and added to its log lines.
+func wrapHandler(route string, next http.Handler) httprouter.Handle {
 	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
-		ctx := context.WithValue(r.Context(), "params", ps)
-		next.ServeHTTP(w, r.WithContext(ctx))
+		start := time.Now()
+		s := settings.Get()
+
+		id := r.Header.Get(logger.RequestIDHeader)
+		if id == "" {
+			id = r.Header.Get("ReferenceID")
+		}
+		info := &logger.RequestInfo{ID: logger.RequestID(id), Route: route}
+		w.Header().Set(logger.RequestIDHeader, info.ID)
+
+		ctx, cancel := context.WithTimeout(r.Context(), s.QueryTimeout(route))
+		defer cancel()
+
+		ctx = logger.WithRequest(ctx, info)
+		ctx = context.WithValue(ctx, "params", ps)
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		logAccess(logger.ForRequest(s.LogLevel, info), r, rec.status, time.Since(start))
+	}
+}
+
+//statusRecorder keeps the status written by the handler for the access log
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	s.status = code
+	s.ResponseWriter.WriteHeader(code)
+}
+
+//logAccess writes the line every request ends with, server errors at ERROR and
+//the rest at INFO
+func logAccess(l *logger.Logger, r *http.Request, status int, latency time.Duration) {
+	l = l.With("method", r.Method).
+		With("path", r.URL.Path).
+		With("status", status).
+		With("latencyMs", float64(latency.Microseconds())/1000)
+
+	if status >= http.StatusInternalServerError {
+		l.Error("Request failed")
+		return
 	}
+	l.Info("Request served")
 }
 
 //RouterConfig function
@@ -40,9 +91,18 @@ func RouterConfig() (router *httprouter.Router) {
 	router = httprouter.New()
 
 	indexHandlers := alice.New(recoverHandler)
+	authHandlers := indexHandlers.Append(authHandler)
 
-	registration(router, indexHandlers)
-	appointment(router, indexHandlers)
+	authentication(router, indexHandlers, authHandlers)
+	registration(router, authHandlers)
+	appointment(router, authHandlers)
+	vaccineCenters(router, indexHandlers, authHandlers)
+	capacities(router, indexHandlers, authHandlers)
+	vaccines(router, indexHandlers, authHandlers)
+	eligibilityPolicies(router, indexHandlers, authHandlers)
+	roles(router, authHandlers)
+	runtimeSettings(router, authHandlers)
+	metrics(router, authHandlers)
 
 	return
 }
@@ -0,0 +1,62 @@
+package routes
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"vaccinationDrive/internals/logger"
+)
+
+func TestWrapHandlerRequestID(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger.SetOutput(buf)
+	defer logger.SetOutput(os.Stdout)
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    string
+	}{
+		{"request id", map[string]string{logger.RequestIDHeader: "client-42"}, "client-42"},
+		{"reference id", map[string]string{"ReferenceID": "ref-42"}, "ref-42"},
+		{"request id before reference id", map[string]string{logger.RequestIDHeader: "client-42", "ReferenceID": "ref-42"}, "client-42"},
+		{"invalid id", map[string]string{logger.RequestIDHeader: "bad id"}, ""},
+		{"no id", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+
+			var info *logger.RequestInfo
+			h := wrapHandler("GET /test", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				info = logger.RequestFrom(r.Context())
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/test", nil)
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			h(w, r, nil)
+
+			id := w.Header().Get(logger.RequestIDHeader)
+			if tt.want != "" && id != tt.want {
+				t.Errorf("response id = %q, want %q", id, tt.want)
+			}
+			if tt.want == "" && (len(id) != 32 || id == tt.headers[logger.RequestIDHeader]) {
+				t.Errorf("response id = %q, want a generated ID", id)
+			}
+			if info == nil || info.ID != id || info.Route != "GET /test" {
+				t.Errorf("request info = %+v, want ID %q and route GET /test", info, id)
+			}
+			if !strings.Contains(buf.String(), `"requestId":"`+id+`"`) {
+				t.Errorf("access log = %s, want requestId %q", buf.String(), id)
+			}
+		})
+	}
+}
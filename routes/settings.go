@@ -0,0 +1,35 @@
+package routes
+
+import (
+	"net/http"
+	"vaccinationDrive/internals/settings"
+	"vaccinationDrive/models"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/justinas/alice"
+)
+
+func runtimeSettings(router *httprouter.Router, authHandlers alice.Chain) {
+	adminHandlers := authHandlers.Append(requireRole(models.RoleAdmin))
+
+	router.GET("/settings", wrapHandler("GET /settings", adminHandlers.ThenFunc(GetSettings)))
+	router.POST("/settings/reload", wrapHandler("POST /settings/reload", adminHandlers.ThenFunc(ReloadSettings)))
+}
+
+func GetSettings(w http.ResponseWriter, r *http.Request) {
+	rd := logAndGetContext(w, r)
+
+	writeJSONStruct(settings.GetStatus(), http.StatusOK, rd)
+}
+
+//ReloadSettings reloads the config file now instead of waiting for the watcher
+func ReloadSettings(w http.ResponseWriter, r *http.Request) {
+	rd := logAndGetContext(w, r)
+
+	if err := settings.Reload(); err != nil {
+		writeProblem(models.NewProblem(http.StatusBadRequest, err.Error()), rd)
+		return
+	}
+
+	writeJSONStruct(settings.GetStatus(), http.StatusOK, rd)
+}
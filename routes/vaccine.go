@@ -0,0 +1,126 @@
+package routes
+
+import (
+	"net/http"
+	"vaccinationDrive/internals/services/vaccine"
+	"vaccinationDrive/models"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/justinas/alice"
+)
+
+func vaccines(router *httprouter.Router, indexHandlers alice.Chain, authHandlers alice.Chain) {
+	adminHandlers := authHandlers.Append(requireRole(models.RoleAdmin))
+
+	router.POST("/vaccines", wrapHandler("POST /vaccines", adminHandlers.ThenFunc(CreateVaccine)))
+	router.GET("/vaccines", wrapHandler("GET /vaccines", indexHandlers.ThenFunc(GetVaccines)))
+	router.GET("/vaccines/:id", wrapHandler("GET /vaccines/:id", indexHandlers.ThenFunc(GetVaccine)))
+	router.PUT("/vaccines/:id", wrapHandler("PUT /vaccines/:id", adminHandlers.ThenFunc(UpdateVaccine)))
+	router.PUT("/vaccines/:id/deactivate", wrapHandler("PUT /vaccines/:id/deactivate", adminHandlers.ThenFunc(DeactivateVaccine)))
+}
+
+func CreateVaccine(w http.ResponseWriter, r *http.Request) {
+	rd := logAndGetContext(w, r)
+
+	vaccineIns := models.Vaccine{}
+
+	if !parseJSON(rd, r.Body, &vaccineIns) {
+		return
+	}
+
+	vaccineIns.Normalize()
+	if errs, err := vaccineIns.Validate(); err != nil {
+		renderValidationError(rd, http.StatusBadRequest, errs)
+		return
+	}
+
+	vacIns := vaccine.NewVaccineData(rd.l, rd.dbConn)
+	res, err := vacIns.CreateVaccine(r.Context(), vaccineIns)
+	if err != nil {
+		writeError(err, rd)
+		return
+	}
+
+	writeJSONStruct(res, http.StatusCreated, rd)
+}
+
+func GetVaccines(w http.ResponseWriter, r *http.Request) {
+	rd := logAndGetContext(w, r)
+
+	activeOnly := r.URL.Query().Get("active") != "false"
+
+	vacIns := vaccine.NewVaccineData(rd.l, rd.dbConn)
+	res, err := vacIns.GetVaccines(r.Context(), activeOnly)
+	if err != nil {
+		writeError(err, rd)
+		return
+	}
+
+	writeJSONStruct(res, http.StatusOK, rd)
+}
+
+func GetVaccine(w http.ResponseWriter, r *http.Request) {
+	rd := logAndGetContext(w, r)
+
+	ID, isErr := GetIDFromParams(rd, "id")
+	if !isErr {
+		return
+	}
+
+	vacIns := vaccine.NewVaccineData(rd.l, rd.dbConn)
+	res, err := vacIns.GetVaccine(r.Context(), ID)
+	if err != nil {
+		writeError(err, rd)
+		return
+	}
+
+	writeJSONStruct(res, http.StatusOK, rd)
+}
+
+func UpdateVaccine(w http.ResponseWriter, r *http.Request) {
+	rd := logAndGetContext(w, r)
+
+	ID, isErr := GetIDFromParams(rd, "id")
+	if !isErr {
+		return
+	}
+
+	vaccineIns := models.Vaccine{}
+
+	if !parseJSON(rd, r.Body, &vaccineIns) {
+		return
+	}
+
+	vaccineIns.ID = ID
+	vaccineIns.Normalize()
+	if errs, err := vaccineIns.Validate(); err != nil {
+		renderValidationError(rd, http.StatusBadRequest, errs)
+		return
+	}
+
+	vacIns := vaccine.NewVaccineData(rd.l, rd.dbConn)
+	res, err := vacIns.UpdateVaccine(r.Context(), vaccineIns)
+	if err != nil {
+		writeError(err, rd)
+		return
+	}
+
+	writeJSONStruct(res, http.StatusOK, rd)
+}
+
+func DeactivateVaccine(w http.ResponseWriter, r *http.Request) {
+	rd := logAndGetContext(w, r)
+
+	ID, isErr := GetIDFromParams(rd, "id")
+	if !isErr {
+		return
+	}
+
+	vacIns := vaccine.NewVaccineData(rd.l, rd.dbConn)
+	if err := vacIns.DeactivateVaccine(r.Context(), ID); err != nil {
+		writeError(err, rd)
+		return
+	}
+
+	writeJSONMessage("Vaccine deactivated successfully", MSG, http.StatusOK, rd)
+}
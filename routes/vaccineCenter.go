@@ -0,0 +1,127 @@
+package routes
+
+import (
+	"net/http"
+	"vaccinationDrive/internals/services/vaccineCenter"
+	"vaccinationDrive/models"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/justinas/alice"
+)
+
+func vaccineCenters(router *httprouter.Router, indexHandlers alice.Chain, authHandlers alice.Chain) {
+	adminHandlers := authHandlers.Append(requireRole(models.RoleAdmin))
+
+	router.POST("/centers", wrapHandler("POST /centers", adminHandlers.ThenFunc(CreateVaccineCenter)))
+	router.GET("/centers", wrapHandler("GET /centers", indexHandlers.ThenFunc(GetVaccineCenters)))
+	router.GET("/centers/:id", wrapHandler("GET /centers/:id", indexHandlers.ThenFunc(GetVaccineCenter)))
+	router.PUT("/centers/:id", wrapHandler("PUT /centers/:id", adminHandlers.ThenFunc(UpdateVaccineCenter)))
+	router.PUT("/centers/:id/deactivate", wrapHandler("PUT /centers/:id/deactivate", adminHandlers.ThenFunc(DeactivateVaccineCenter)))
+}
+
+func CreateVaccineCenter(w http.ResponseWriter, r *http.Request) {
+	rd := logAndGetContext(w, r)
+
+	center := models.VaccineCenter{}
+
+	if !parseJSON(rd, r.Body, &center) {
+		return
+	}
+
+	center.Normalize()
+	if errs, err := center.Validate(); err != nil {
+		renderValidationError(rd, http.StatusBadRequest, errs)
+		return
+	}
+
+	centerIns := vaccineCenter.NewVaccineCenterData(rd.l, rd.dbConn)
+	res, err := centerIns.CreateVaccineCenter(r.Context(), center)
+	if err != nil {
+		writeError(err, rd)
+		return
+	}
+
+	writeJSONStruct(res, http.StatusCreated, rd)
+}
+
+func GetVaccineCenters(w http.ResponseWriter, r *http.Request) {
+	rd := logAndGetContext(w, r)
+
+	district := r.URL.Query().Get("district")
+	activeOnly := r.URL.Query().Get("active") != "false"
+
+	centerIns := vaccineCenter.NewVaccineCenterData(rd.l, rd.dbConn)
+	res, err := centerIns.GetVaccineCenters(r.Context(), district, activeOnly)
+	if err != nil {
+		writeError(err, rd)
+		return
+	}
+
+	writeJSONStruct(res, http.StatusOK, rd)
+}
+
+func GetVaccineCenter(w http.ResponseWriter, r *http.Request) {
+	rd := logAndGetContext(w, r)
+
+	ID, isErr := GetIDFromParams(rd, "id")
+	if !isErr {
+		return
+	}
+
+	centerIns := vaccineCenter.NewVaccineCenterData(rd.l, rd.dbConn)
+	res, err := centerIns.GetVaccineCenter(r.Context(), ID)
+	if err != nil {
+		writeError(err, rd)
+		return
+	}
+
+	writeJSONStruct(res, http.StatusOK, rd)
+}
+
+func UpdateVaccineCenter(w http.ResponseWriter, r *http.Request) {
+	rd := logAndGetContext(w, r)
+
+	ID, isErr := GetIDFromParams(rd, "id")
+	if !isErr {
+		return
+	}
+
+	center := models.VaccineCenter{}
+
+	if !parseJSON(rd, r.Body, &center) {
+		return
+	}
+
+	center.ID = ID
+	center.Normalize()
+	if errs, err := center.Validate(); err != nil {
+		renderValidationError(rd, http.StatusBadRequest, errs)
+		return
+	}
+
+	centerIns := vaccineCenter.NewVaccineCenterData(rd.l, rd.dbConn)
+	res, err := centerIns.UpdateVaccineCenter(r.Context(), center)
+	if err != nil {
+		writeError(err, rd)
+		return
+	}
+
+	writeJSONStruct(res, http.StatusOK, rd)
+}
+
+func DeactivateVaccineCenter(w http.ResponseWriter, r *http.Request) {
+	rd := logAndGetContext(w, r)
+
+	ID, isErr := GetIDFromParams(rd, "id")
+	if !isErr {
+		return
+	}
+
+	centerIns := vaccineCenter.NewVaccineCenterData(rd.l, rd.dbConn)
+	if err := centerIns.DeactivateVaccineCenter(r.Context(), ID); err != nil {
+		writeError(err, rd)
+		return
+	}
+
+	writeJSONMessage("Vaccine center deactivated successfully", MSG, http.StatusOK, rd)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"log"
+	"vaccinationDrive/dbcon"
+	"vaccinationDrive/internals/services/userRegistration"
+	"vaccinationDrive/internals/services/vaccine"
+	"vaccinationDrive/internals/services/vaccineCenter"
+	"vaccinationDrive/models"
+)
+
+var seedCenters = []models.VaccineCenter{
+	{Name: "Government General Hospital", Address: "Park Town", District: "Chennai", Pincode: "600003", OpeningTime: "09:00", ClosingTime: "17:00"},
+	{Name: "Urban Primary Health Centre", Address: "Adyar", District: "Chennai", Pincode: "600020", OpeningTime: "08:00", ClosingTime: "14:00"},
+	{Name: "District Headquarters Hospital", Address: "Race Course Road", District: "Coimbatore", Pincode: "641018", OpeningTime: "09:00", ClosingTime: "18:00"},
+}
+
+var seedVaccines = []models.Vaccine{
+	{Name: "Covishield", Manufacturer: "Serum Institute of India", NumberOfDoses: 2, MinAge: 18, MaxAge: 120,
+		DoseIntervals: []models.DoseInterval{{Dose: 2, MinDays: 84, MaxDays: 112}}},
+	{Name: "Covaxin", Manufacturer: "Bharat Biotech", NumberOfDoses: 2, MinAge: 18, MaxAge: 120,
+		DoseIntervals: []models.DoseInterval{{Dose: 2, MinDays: 28, MaxDays: 42}}},
+}
+
+var seedUsers = []models.User{
+	{Name: "Sample Beneficiary One", DOB: "15/08/1960", IDType: models.IDTypeAadhaar, IDNumber: "499118665246", PhoneNumber: "9000000001"},
+	{Name: "Sample Beneficiary Two", DOB: "02/10/1985", IDType: models.IDTypePAN, IDNumber: "ABCPE1234F", PhoneNumber: "9000000001"},
+	{Name: "Sample Beneficiary Three", DOB: "26/01/1995", IDType: models.IDTypePassport, IDNumber: "J8369854", PhoneNumber: "9000000002"},
+	{Name: "Sample Beneficiary Four", DOB: "14/11/1972", IDType: models.IDTypeVoterID, IDNumber: "ABC1234567", PhoneNumber: "9000000003"},
+}
+
+//runSeed adds the sample data for development, entries that are already present
+//are skipped so it can be run again
+func runSeed(args []string) error {
+	ctx := context.Background()
+	l := newLogger()
+	db := dbcon.Get()
+
+	centerIns := vaccineCenter.NewVaccineCenterData(l, db)
+	for _, center := range seedCenters {
+		center.Normalize()
+		if errs, err := center.Validate(); err != nil {
+			log.Printf("Skipping center %s: %v", center.Name, errs)
+			continue
+		}
+
+		res, err := centerIns.CreateVaccineCenter(ctx, center)
+		if err == models.ErrVaccineCenterExists {
+			log.Printf("Skipping center %s: %v", center.Name, err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		log.Printf("Added center %d %s", res.ID, res.Name)
+	}
+
+	vaccineIns := vaccine.NewVaccineData(l, db)
+	for _, vc := range seedVaccines {
+		vc.Normalize()
+		if errs, err := vc.Validate(); err != nil {
+			log.Printf("Skipping vaccine %s: %v", vc.Name, errs)
+			continue
+		}
+
+		res, err := vaccineIns.CreateVaccine(ctx, vc)
+		if err == models.ErrVaccineExists {
+			log.Printf("Skipping vaccine %s: %v", vc.Name, err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		log.Printf("Added vaccine %d %s", res.ID, res.Name)
+	}
+
+	userIns := userRegistration.NewUserData(l, db)
+	for _, user := range seedUsers {
+		user.Normalize()
+		if errs, err := user.Validate(); err != nil {
+			log.Printf("Skipping user %s: %v", user.Name, errs)
+			continue
+		}
+
+		res, err := userIns.RegisterUser(ctx, user)
+		if err == models.ErrIDNumberRegistered {
+			log.Printf("Skipping user %s: %v", user.Name, err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		log.Printf("Added user %d %s under %s", res.ID, res.Name, res.PhoneNumber)
+	}
+
+	return nil
+}
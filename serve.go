@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+	"vaccinationDrive/conf"
+	"vaccinationDrive/dbcon"
+	"vaccinationDrive/dbscripts"
+	"vaccinationDrive/internals/logger"
+	"vaccinationDrive/internals/settings"
+	"vaccinationDrive/routes"
+
+	"github.com/rs/cors"
+)
+
+//runServe starts the http server once the schema is migrated
+func runServe(args []string) error {
+	//The server logs as json, the lines of the log package included
+	log.SetFlags(0)
+	log.SetOutput(logger.StdWriter())
+
+	dbscripts.InitDB()
+
+	stopWatching := settings.Watch(5 * time.Second)
+	defer stopWatching()
+
+	router := routes.RouterConfig()
+	//r := chi.NewRouter()
+
+	c := cors.New(cors.Options{
+		AllowedOrigins: []string{"*"},
+		AllowedMethods: []string{"GET", "POST", "DELETE", "PUT", "OPTIONS"},
+		AllowedHeaders: []string{"Origin", "X-Requested-With", "Content-Type", "Accept",
+			"Authorization", "Access-Control-Allow-Headers", "Access-Control-Allow-Origin", logger.RequestIDHeader},
+		ExposedHeaders: []string{logger.RequestIDHeader},
+	})
+
+	server := http.Server{
+		Addr:         fmt.Sprintf(":%d", conf.Cfg.PORT),
+		ReadTimeout:  90 * time.Second,
+		WriteTimeout: 90 * time.Second,
+		Handler:      c.Handler(router),
+	}
+
+	done := make(chan bool)
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt)
+
+	//Graceful shut down
+	go func() {
+		<-quit
+		log.Println("Server is shutting down...")
+
+		//Close resources before shut down
+		dbcon.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		//Shutdown server
+		server.SetKeepAlivesEnabled(false)
+		if err := server.Shutdown(ctx); err != nil {
+			log.Fatalf("Unable to gracefully shutdown the server: %v\n", err)
+		}
+
+		//Close channels
+		close(quit)
+		close(done)
+	}()
+
+	log.Printf("Listening on: %d", conf.Cfg.PORT)
+
+	if err := server.ListenAndServe(); err != http.ErrServerClosed {
+		log.Fatalf("Error in listening server: %s", err.Error())
+	}
+	<-done
+	log.Println("Server stopped")
+	return nil
+}
@@ -23,6 +23,9 @@ const (
 	DFMMMyyyyNoSep = "Jan2006"
 )
 
+//IST is the zone appointment dates and slots are expressed in
+var IST = time.FixedZone("IST", 5*60*60+30*60)
+
 //StringyyyyMMddToDate is func to parse string as date
 func StringyyyyMMddToDate(dateStr string) (time.Time, error) {
 	// log.Printf("string to date %+v", dateStr)
@@ -41,11 +44,52 @@ func StringddMMyyyyToDate(dateStr string) (time.Time, error) {
 	return time.ParseInLocation(DFddMMyyyy, dateStr, time.UTC)
 }
 
+//SlotStartTime returns the start of a time slot like "10:00-11:00" on a dd/MM/yyyy date in IST.
+//When the slot has no parsable start time the start of the day is returned.
+func SlotStartTime(dateStr, slot string) (time.Time, error) {
+	date, err := time.ParseInLocation(DFddMMyyyy, dateStr, IST)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	start := strings.TrimSpace(strings.SplitN(slot, "-", 2)[0])
+	clock, err := time.Parse("15:04", start)
+	if err != nil {
+		return date, nil
+	}
+
+	return date.Add(time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute), nil
+}
+
+//AgeOn returns the age in completed years on the given date.
+//A 29 Feb birthday is completed on 1 Mar in non leap years.
+func AgeOn(dob, on time.Time) int {
+	age := on.Year() - dob.Year()
+	if on.Month() < dob.Month() || (on.Month() == dob.Month() && on.Day() < dob.Day()) {
+		age--
+	}
+	return age
+}
+
+//DaysBetween returns the number of calendar days in IST from one time to another
+func DaysBetween(from, to time.Time) int {
+	f := from.In(IST)
+	t := to.In(IST)
+	fromDay := time.Date(f.Year(), f.Month(), f.Day(), 0, 0, 0, 0, time.UTC)
+	toDay := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return int(toDay.Sub(fromDay).Hours() / 24)
+}
+
 //DateToStringDFddMMMyyyy is func to format date as string
 func DateToStringDFddMMMyyyy(date time.Time) string {
 	return date.Format(DFddMMMyyyy)
 }
 
+//DateToStringDFddMMyyyy is func to format date as string
+func DateToStringDFddMMyyyy(date time.Time) string {
+	return date.Format(DFddMMyyyy)
+}
+
 //DateToStringDFyyyyMMdd is func to format date as string
 func DateToStringDFyyyyMMdd(date time.Time) string {
 	return date.Format(DFyyyyMMdd)
@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAgeOn(t *testing.T) {
+	date := func(s string) time.Time {
+		d, err := StringddMMyyyyToDate(s)
+		if err != nil {
+			t.Fatalf("invalid date %s: %v", s, err)
+		}
+		return d
+	}
+
+	tests := []struct {
+		name    string
+		dob, on string
+		age     int
+	}{
+		{"birthday on the date", "15/08/1960", "15/08/2021", 61},
+		{"day before the birthday", "15/08/1960", "14/08/2021", 60},
+		{"day after the birthday", "15/08/1960", "16/08/2021", 61},
+		{"earlier month", "15/08/1960", "31/07/2021", 60},
+		{"born on the date", "01/06/2021", "01/06/2021", 0},
+		{"leap day in a leap year", "29/02/2000", "29/02/2024", 24},
+		{"leap day before the birthday", "29/02/2000", "28/02/2024", 23},
+		{"leap day on 28 Feb of a non leap year", "29/02/2000", "28/02/2021", 20},
+		{"leap day on 1 Mar of a non leap year", "29/02/2000", "01/03/2021", 21},
+		{"born 1 Mar on 29 Feb", "01/03/2000", "29/02/2024", 23},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AgeOn(date(tt.dob), date(tt.on)); got != tt.age {
+				t.Errorf("AgeOn(%s, %s) = %d, want %d", tt.dob, tt.on, got, tt.age)
+			}
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"reflect"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -267,6 +268,156 @@ func Regex(v interface{}, param string) error {
 	return nil
 }
 
+// aadhaarPattern matches 12 digits, Aadhaar numbers never start with 0 or 1
+var aadhaarPattern = regexp.MustCompile(`^[2-9][0-9]{11}$`)
+
+// verhoeff tables for multiplication, permutation and inverse
+var (
+	verhoeffD = [10][10]int{
+		{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		{1, 2, 3, 4, 0, 6, 7, 8, 9, 5},
+		{2, 3, 4, 0, 1, 7, 8, 9, 5, 6},
+		{3, 4, 0, 1, 2, 8, 9, 5, 6, 7},
+		{4, 0, 1, 2, 3, 9, 5, 6, 7, 8},
+		{5, 9, 8, 7, 6, 0, 4, 3, 2, 1},
+		{6, 5, 9, 8, 7, 1, 0, 4, 3, 2},
+		{7, 6, 5, 9, 8, 2, 1, 0, 4, 3},
+		{8, 7, 6, 5, 9, 3, 2, 1, 0, 4},
+		{9, 8, 7, 6, 5, 4, 3, 2, 1, 0},
+	}
+	verhoeffP = [8][10]int{
+		{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		{1, 5, 7, 6, 2, 8, 3, 0, 9, 4},
+		{5, 8, 0, 3, 7, 9, 6, 1, 4, 2},
+		{8, 9, 1, 6, 0, 4, 3, 5, 2, 7},
+		{9, 4, 5, 3, 1, 2, 6, 8, 7, 0},
+		{4, 2, 8, 6, 5, 7, 3, 9, 0, 1},
+		{2, 7, 9, 3, 8, 0, 6, 4, 1, 5},
+		{7, 0, 4, 6, 9, 1, 3, 2, 5, 8},
+	}
+)
+
+// ID document number formats, matched after NormalizeIDNumber
+var (
+	// panPattern is 5 letters with the holder type as the 4th, 4 digits and a letter
+	panPattern = regexp.MustCompile(`^[A-Z]{3}[ABCFGHLJPT][A-Z][0-9]{4}[A-Z]$`)
+	// passportPattern is a series letter and 7 digits, Q, X and Z are not issued
+	passportPattern = regexp.MustCompile(`^[A-PR-WY][1-9][0-9]{5}[1-9]$`)
+	// voterIDPattern is the EPIC number, 3 letters and 7 digits
+	voterIDPattern = regexp.MustCompile(`^[A-Z]{3}[0-9]{7}$`)
+	// drivingLicencePattern is the state code, RTO code, year of issue and 7 digits
+	drivingLicencePattern = regexp.MustCompile(`^[A-Z]{2}[0-9]{2}(19|20)[0-9]{2}[0-9]{7}$`)
+	// pensionPassbookPattern is the 12 digit pension payment order number
+	pensionPassbookPattern = regexp.MustCompile(`^[0-9]{12}$`)
+)
+
+// NormalizeAadhaar removes the spaces and hyphens people group the digits with
+func NormalizeAadhaar(s string) string {
+	return NormalizeIDNumber(s)
+}
+
+// NormalizeIDNumber uppercases the ID document number and removes the
+// spaces and hyphens people group it with
+func NormalizeIDNumber(s string) string {
+	return strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(s)))
+}
+
+// Aadhaar is the builtin validation function that checks whether the
+// string variable is a 12 digit Aadhaar number with a valid Verhoeff
+// check digit. Spaces and hyphens between the digits are ignored and
+// empty strings are left to 'required'.
+func Aadhaar(v interface{}, param string) error {
+	s, ok := v.(string)
+	if !ok {
+		sptr, ok := v.(*string)
+		if !ok {
+			return errUnsupported
+		}
+		if sptr == nil {
+			return nil
+		}
+		s = *sptr
+	}
+
+	if s == "" {
+		return nil
+	}
+
+	s = NormalizeAadhaar(s)
+	if !aadhaarPattern.MatchString(s) || !verhoeffValid(s) {
+		return ErrValidation
+	}
+
+	return nil
+}
+
+// PAN is the builtin validation function that checks whether the string
+// variable is a valid Permanent Account Number
+func PAN(v interface{}, param string) error {
+	return matchIDNumber(v, panPattern)
+}
+
+// Passport is the builtin validation function that checks whether the string
+// variable is a valid Indian passport number
+func Passport(v interface{}, param string) error {
+	return matchIDNumber(v, passportPattern)
+}
+
+// VoterID is the builtin validation function that checks whether the string
+// variable is a valid voter ID (EPIC) number
+func VoterID(v interface{}, param string) error {
+	return matchIDNumber(v, voterIDPattern)
+}
+
+// DrivingLicence is the builtin validation function that checks whether the
+// string variable is a valid driving licence number
+func DrivingLicence(v interface{}, param string) error {
+	return matchIDNumber(v, drivingLicencePattern)
+}
+
+// PensionPassbook is the builtin validation function that checks whether the
+// string variable is a valid pension payment order number
+func PensionPassbook(v interface{}, param string) error {
+	return matchIDNumber(v, pensionPassbookPattern)
+}
+
+// matchIDNumber normalizes the string variable and matches it against the
+// pattern, empty strings are left to 'required'
+func matchIDNumber(v interface{}, re *regexp.Regexp) error {
+	s, ok := v.(string)
+	if !ok {
+		sptr, ok := v.(*string)
+		if !ok {
+			return errUnsupported
+		}
+		if sptr == nil {
+			return nil
+		}
+		s = *sptr
+	}
+
+	if s == "" {
+		return nil
+	}
+
+	if !re.MatchString(NormalizeIDNumber(s)) {
+		return ErrValidation
+	}
+
+	return nil
+}
+
+// verhoeffValid checks the Verhoeff checksum of a string of digits,
+// the last digit being the check digit
+func verhoeffValid(digits string) bool {
+	c := 0
+	for i := 0; i < len(digits); i++ {
+		d := int(digits[len(digits)-1-i] - '0')
+		c = verhoeffD[c][verhoeffP[i%8][d]]
+	}
+	return c == 0
+}
+
 //Email validation using regex
 func Email(v interface{}, param string) error {
 	emailRegexString := "^(?:(?:(?:(?:[a-zA-Z]|\\d|[!#\\$%&'\\*\\+\\-\\/=\\?\\^_`{\\|}~]|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])+(?:\\.([a-zA-Z]|\\d|[!#\\$%&'\\*\\+\\-\\/=\\?\\^_`{\\|}~]|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])+)*)|(?:(?:\\x22)(?:(?:(?:(?:\\x20|\\x09)*(?:\\x0d\\x0a))?(?:\\x20|\\x09)+)?(?:(?:[\\x01-\\x08\\x0b\\x0c\\x0e-\\x1f\\x7f]|\\x21|[\\x23-\\x5b]|[\\x5d-\\x7e]|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])|(?:\\(?:[\\x01-\\x09\\x0b\\x0c\\x0d-\\x7f]|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}]))))*(?:(?:(?:\\x20|\\x09)*(?:\\x0d\\x0a))?(\\x20|\\x09)+)?(?:\\x22)))@(?:(?:(?:[a-zA-Z]|\\d|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])|(?:(?:[a-zA-Z]|\\d|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])(?:[a-zA-Z]|\\d|-|\\.|_|~|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])*(?:[a-zA-Z]|\\d|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])))\\.)+(?:(?:[a-zA-Z]|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])|(?:(?:[a-zA-Z]|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])(?:[a-zA-Z]|\\d|-|\\.|_|~|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])*(?:[a-zA-Z]|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])))\\.?$"
@@ -0,0 +1,111 @@
+package validator
+
+import "testing"
+
+func TestVerhoeffValid(t *testing.T) {
+	tests := []struct {
+		digits string
+		valid  bool
+	}{
+		{"2363", true},
+		{"2364", false},
+		{"499118665246", true},
+		{"499118665247", false},
+		{"499118665264", false},
+		{"0", true},
+	}
+
+	for _, tt := range tests {
+		if got := verhoeffValid(tt.digits); got != tt.valid {
+			t.Errorf("verhoeffValid(%q) = %v, want %v", tt.digits, got, tt.valid)
+		}
+	}
+}
+
+func TestAadhaar(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  error
+	}{
+		{"valid", "499118665246", nil},
+		{"grouped with spaces", "4991 1866 5246", nil},
+		{"grouped with hyphens", " 4991-1866-5246 ", nil},
+		{"wrong check digit", "499118665247", ErrValidation},
+		{"starts with 1", "199118665246", ErrValidation},
+		{"too short", "49911866524", ErrValidation},
+		{"letters", "49911866524A", ErrValidation},
+		{"empty is left to required", "", nil},
+		{"pointer", strPtr("499118665246"), nil},
+		{"nil pointer", (*string)(nil), nil},
+		{"unsupported type", 499118665246, errUnsupported},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Aadhaar(tt.value, ""); got != tt.want {
+				t.Errorf("Aadhaar(%v) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeIDNumber(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"4991 1866 5246", "499118665246"},
+		{"4991-1866-5246", "499118665246"},
+		{" abcpe1234f ", "ABCPE1234F"},
+		{"tn-01 2010 0012345", "TN0120100012345"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeIDNumber(tt.in); got != tt.want {
+			t.Errorf("NormalizeIDNumber(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestIDNumberFormats(t *testing.T) {
+	tests := []struct {
+		name  string
+		fn    ValidationFunc
+		value string
+		want  error
+	}{
+		{"pan", PAN, "ABCPE1234F", nil},
+		{"pan lowercase", PAN, "abcpe1234f", nil},
+		{"pan invalid holder type", PAN, "ABCXE1234F", ErrValidation},
+		{"pan short", PAN, "ABCPE123F", ErrValidation},
+
+		{"passport", Passport, "J8369854", nil},
+		{"passport series Q", Passport, "Q8369854", ErrValidation},
+		{"passport ends with 0", Passport, "J8369850", ErrValidation},
+
+		{"voter id", VoterID, "ABC1234567", nil},
+		{"voter id grouped", VoterID, "ABC-123-4567", nil},
+		{"voter id digits", VoterID, "1231234567", ErrValidation},
+
+		{"driving licence", DrivingLicence, "TN0120100012345", nil},
+		{"driving licence grouped", DrivingLicence, "TN01 2010 0012345", nil},
+		{"driving licence year", DrivingLicence, "TN0118100012345", ErrValidation},
+
+		{"pension passbook", PensionPassbook, "123456789012", nil},
+		{"pension passbook letters", PensionPassbook, "12345678901A", ErrValidation},
+
+		{"empty is left to required", PAN, "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.fn(tt.value, ""); got != tt.want {
+				t.Errorf("%s(%q) = %v, want %v", tt.name, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
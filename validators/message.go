@@ -42,16 +42,22 @@ var defaultMessages Messages
 
 func setDefaultMessage() {
 	defaultMessages = Messages{
-		"required":  "${0} ${1} cannot be blank",
-		"email":     "${0} ${2} is not a valid e-mail address",
-		"matches":   "${0} ${2} does not match the required pattern ${3}",
-		"len":       "${0} ${2} should be in size ${3}",
-		"min":       "${0} ${2} is less than minimum value ${3}",
-		"max":       "${0} ${2} exceeds maximum value ${3}",
-		"inList":    "${0} ${2} is not contained within the list ${3}",
-		"unique":    "${0} ${2} already exists",
-		"pinunique": "${0} ${2} already exists",
-		"regexp":    "${0} ${2} is not a valid data",
+		"required":        "${0} ${1} cannot be blank",
+		"email":           "${0} ${2} is not a valid e-mail address",
+		"matches":         "${0} ${2} does not match the required pattern ${3}",
+		"len":             "${0} ${2} should be in size ${3}",
+		"min":             "${0} ${2} is less than minimum value ${3}",
+		"max":             "${0} ${2} exceeds maximum value ${3}",
+		"inList":          "${0} ${2} is not contained within the list ${3}",
+		"unique":          "${0} ${2} already exists",
+		"pinunique":       "${0} ${2} already exists",
+		"regexp":          "${0} ${2} is not a valid data",
+		"aadhaar":         "${0} ${1} is not a valid Aadhaar number",
+		"pan":             "${0} ${1} is not a valid PAN",
+		"passport":        "${0} ${1} is not a valid passport number",
+		"voterId":         "${0} ${1} is not a valid voter ID number",
+		"drivingLicence":  "${0} ${1} is not a valid driving licence number",
+		"pensionPassbook": "${0} ${1} is not a valid pension passbook number",
 	}
 }
 
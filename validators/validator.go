@@ -54,13 +54,19 @@ func New(module string) *Validator {
 	return &Validator{
 		tagName: "validate",
 		validationFuncs: map[string]ValidationFunc{
-			"required": Required,
-			"len":      Length,
-			"min":      Min,
-			"max":      Max,
-			"email":    Email,
-			"regexp":   Regex,
-			"inList":   InList,
+			"required":        Required,
+			"len":             Length,
+			"min":             Min,
+			"max":             Max,
+			"email":           Email,
+			"regexp":          Regex,
+			"inList":          InList,
+			"aadhaar":         Aadhaar,
+			"pan":             PAN,
+			"passport":        Passport,
+			"voterId":         VoterID,
+			"drivingLicence":  DrivingLicence,
+			"pensionPassbook": PensionPassbook,
 		},
 		errors: Errors{},
 		module: module,